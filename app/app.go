@@ -3,59 +3,247 @@ package app
 import (
 	"context"
 	"errors"
-	"log"
+	"fmt"
 	"net"
 	"net/http"
+	"reflect"
+	"slices"
 	"sync"
 	"time"
 
+	"go.uber.org/multierr"
 	"go.uber.org/zap"
 
+	"github.com/cyber/test-project/appcontext"
+	"github.com/cyber/test-project/auth"
+	"github.com/cyber/test-project/cassette"
 	"github.com/cyber/test-project/clients"
 	"github.com/cyber/test-project/config"
+	"github.com/cyber/test-project/health"
+	"github.com/cyber/test-project/lifecycle"
 	"github.com/cyber/test-project/logging"
+	"github.com/cyber/test-project/metrics"
 	"github.com/cyber/test-project/services"
+	"github.com/cyber/test-project/tracing"
 )
 
+const userAgent = "test-project"
+
 type Application struct {
-	Config       config.Config
-	server       *http.Server
-	shutdownOnce sync.Once
+	configWatcher *config.Watcher
+	lifecycle     *lifecycle.Lifecycle
+	server        *http.Server
+	shutdownOnce  sync.Once
+	shutdownErr   error
+
+	cassette       *cassette.Transport
+	dataDumper     *services.AsanaDataDumper
+	asanaService   *services.AsanaService
+	circuitBreaker *clients.ReloadableCircuitBreaker
+	rateLimiter    *clients.ReloadableRateLimiter
+	readiness      *health.Checker
 }
 
 func InitApplication(configPath string) (*Application, error) {
-	cfg, err := config.ReadConfig(configPath)
+	configWatcher, err := config.NewWatcher(configPath)
 	if err != nil {
 		return nil, err
 	}
 
+	cfg := configWatcher.Config()
+
 	err = logging.Init(cfg.Logging)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Application{
-		Config: cfg,
-	}, nil
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing)
+	if err != nil {
+		return nil, err
+	}
+
+	app := &Application{
+		configWatcher: configWatcher,
+		lifecycle:     lifecycle.New(),
+	}
+	err = app.initServices()
+	if err != nil {
+		return nil, err
+	}
+	app.registerHooks(shutdownTracing)
+
+	configWatcher.OnChange(app.applyConfig)
+	configWatcher.OnError(func(err error) {
+		logging.Logger.Error("configuration reload rejected, keeping previous configuration", zap.Error(err))
+	})
+
+	return app, nil
 }
 
-func (app *Application) Start() error {
-	log.Println("Starting application")
+// Config returns the configuration in effect, including reloaded changes.
+func (app *Application) Config() config.Config {
+	return app.configWatcher.Config()
+}
 
-	baseHttpClient := http.Client{}
+func (app *Application) initServices() error {
+	cfg := app.Config()
 
-	dataDumper := services.NewAsanaDataDumper(app.Config.DataDumper)
+	baseHttpClient, err := clients.NewBaseClient(cfg.Asana.Transport)
+	if err != nil {
+		return fmt.Errorf("asana.transport: %w", err)
+	}
+
+	if cfg.Asana.Cassette.Mode != config.CassetteModeOff {
+		transport, err := newCassetteTransport(cfg, baseHttpClient.Transport)
+		if err != nil {
+			return err
+		}
+		baseHttpClient.Transport = transport
+		app.cassette = transport
+
+		logging.Logger.Warn("asana requests go through a cassette",
+			zap.String("mode", cfg.Asana.Cassette.Mode),
+			zap.String("path", cfg.Asana.Cassette.Path),
+		)
+	}
+
+	app.dataDumper = services.NewAsanaDataDumper(cfg.DataDumper)
+	app.circuitBreaker = clients.NewCircuitBreaker(cfg.CircuitBreaker)
+	metrics.RegisterCircuitBreaker("asana", app.circuitBreaker.StateValue)
+	app.rateLimiter = clients.NewRateLimiter(cfg.Asana.RateLimit)
 
 	asanaClientOptions := clients.ClientOptions{
-		ServiceName: "asana",
-		BaseClient:  &baseHttpClient,
-		BaseURL:     app.Config.Asana.BaseURL,
+		ServiceName:    "asana",
+		BaseClient:     baseHttpClient,
+		BaseURL:        cfg.Asana.BaseURL,
+		CircuitBreaker: app.circuitBreaker,
+		RateLimiter:    app.rateLimiter,
+		Middlewares: []clients.Middleware{
+			clients.UserAgent(userAgent),
+			clients.Retry("asana", cfg.Asana.Retry),
+			clients.Logging(),
+		},
 	}
 	asanaClient := clients.NewAsanaClient(asanaClientOptions)
-	asanaService := services.NewAsanaService(asanaClient, app.Config.Asana.AccessToken, dataDumper)
+	app.asanaService = services.NewAsanaService(asanaClient, cfg.Asana, app.dataDumper)
+
+	app.readiness = health.NewChecker(cfg.Health.CheckTimeout)
+	app.readiness.Register("lifecycle", app.checkReady)
+	app.readiness.Register("data_dumper", app.dataDumper.CheckWritable)
+	app.readiness.Register("circuit_breaker:asana", app.circuitBreaker.Check)
+	if cfg.Health.AsanaProbe.Enabled {
+		app.readiness.Register("asana", health.Cached(app.asanaService.Ping, cfg.Health.AsanaProbe.CacheTTL))
+	}
+
+	return nil
+}
+
+// newAuthenticator returns nil when authentication is disabled, which leaves the API open.
+func newAuthenticator(cfg config.Config) (*auth.Authenticator, error) {
+	if !cfg.Auth.Enabled {
+		logging.Logger.Warn("authentication is disabled, the API is open to anyone who can reach " + cfg.Http.Addr + " and log levels can be changed from loopback addresses")
+		return nil, nil
+	}
+
+	authenticator, err := auth.NewAuthenticator(cfg.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("auth: %w", err)
+	}
+
+	return authenticator, nil
+}
+
+func newCassetteTransport(cfg config.Config, next http.RoundTripper) (*cassette.Transport, error) {
+	redactor, err := logging.NewRedactor(cfg.Logging.Redaction)
+	if err != nil {
+		return nil, err
+	}
+
+	return cassette.NewTransport(cfg.Asana.Cassette, redactor, next)
+}
+
+func (app *Application) checkReady(context.Context) error {
+	if !app.lifecycle.Ready() {
+		return errors.New("application is not ready")
+	}
+
+	return nil
+}
+
+func (app *Application) registerHooks(shutdownTracing tracing.ShutdownFunc) {
+	app.lifecycle.Append(lifecycle.Hook{
+		Name:   "logger",
+		OnStop: syncLogger,
+	})
+
+	app.lifecycle.Append(lifecycle.Hook{
+		Name:   "tracing",
+		OnStop: shutdownTracing,
+	})
+
+	app.lifecycle.Append(lifecycle.Hook{
+		Name:   "data_dumper",
+		OnStop: app.dataDumper.Close,
+	})
+
+	if app.cassette != nil {
+		app.lifecycle.Append(lifecycle.Hook{
+			Name:   "cassette",
+			OnStop: func(context.Context) error { return app.cassette.Close() },
+		})
+	}
+}
+
+// Run starts all components and blocks until ctx is cancelled or a component fails, then shuts
+// everything down. Start, runtime and shutdown errors are all returned.
+func (app *Application) Run(ctx context.Context) error {
+	logging.Logger.Info("starting application")
+
+	// Only the long-running service follows changes of the file, one-shot commands keep the
+	// configuration they started with.
+	app.lifecycle.Append(lifecycle.Hook{
+		Name:    "config_watcher",
+		OnStart: func(context.Context) error { return app.configWatcher.Watch() },
+		OnStop:  func(context.Context) error { return app.configWatcher.Stop() },
+	})
+
+	app.lifecycle.Append(lifecycle.Hook{
+		Name:    "http_server",
+		OnStart: app.startServer,
+		OnStop:  app.stopServer,
+	})
+
+	startErr := app.lifecycle.Start(ctx)
+	if startErr == nil {
+		logging.Logger.Info("application started")
+
+		select {
+		case <-ctx.Done():
+			logging.Logger.Info("application shutdown requested")
+		case <-app.lifecycle.Failed():
+			logging.Logger.Error("application component failed", zap.Error(app.lifecycle.Err()))
+		}
+	}
+
+	stopErr := app.Shutdown()
+
+	return multierr.Combine(startErr, app.lifecycle.Err(), stopErr)
+}
+
+func (app *Application) startServer(context.Context) error {
+	cfg := app.Config()
+
+	authenticator, err := newAuthenticator(cfg)
+	if err != nil {
+		return err
+	}
 
 	routerConfig := RouterConfig{
-		AsanaService: asanaService,
+		AsanaService:   app.asanaService,
+		Readiness:      app.readiness,
+		RequestTimeout: cfg.Http.RequestTimeout,
+		LegacyRoutes:   cfg.Http.LegacyRoutes,
+		Authenticator:  authenticator,
 	}
 
 	router, err := NewRouter(routerConfig)
@@ -64,56 +252,180 @@ func (app *Application) Start() error {
 	}
 
 	app.server = &http.Server{
-		Addr:    app.Config.Http.Addr,
+		Addr:    cfg.Http.Addr,
 		Handler: router,
 	}
 
 	listener, err := net.Listen("tcp", app.server.Addr)
-	if err == nil {
-		logging.Logger.Info("service started at", zap.String("address", app.server.Addr))
-		err = app.server.Serve(listener)
+	if err != nil {
+		return err
 	}
 
-	if errors.Is(err, http.ErrServerClosed) {
-		logging.Logger.Info("HTTP service stopped")
-		return nil
+	logging.Logger.Info("service started at", zap.String("address", app.server.Addr))
+
+	go func() {
+		err := app.server.Serve(listener)
+		if errors.Is(err, http.ErrServerClosed) {
+			logging.Logger.Info("HTTP service stopped")
+			return
+		}
+
+		logging.Logger.Error("HTTP service failed", zap.Error(err))
+		app.lifecycle.Fail(fmt.Errorf("http_server: %w", err))
+	}()
+
+	return nil
+}
+
+func (app *Application) stopServer(ctx context.Context) error {
+	logging.Logger.Info("draining HTTP service")
+
+	return app.server.Shutdown(ctx)
+}
+
+func syncLogger(context.Context) error {
+	err := logging.Logger.Sync()
+	if err != nil {
+		logging.Logger.Error("error calling logger.Sync()", zap.Error(err))
 	}
 
+	return nil
+}
+
+func (app *Application) Reload() {
+	logging.Logger.Info("reloading configuration")
+
+	err := app.configWatcher.Reload()
 	if err != nil {
-		logging.Logger.Error("HTTP service failed", zap.Error(err))
+		logging.Logger.Error("configuration reload rejected, keeping previous configuration", zap.Error(err))
+	}
+}
+
+func (app *Application) applyConfig(prev, next config.Config) {
+	logger := logging.Logger
+
+	err := logging.SetLevel(next.Logging.Level)
+	if err != nil {
+		logger.Error("failed to apply log level", zap.Error(err), zap.String("level", next.Logging.Level))
+	}
+
+	err = logging.SetComponentLevels(next.Logging.Levels)
+	if err != nil {
+		logger.Error("failed to apply component log levels", zap.Error(err))
+	}
+
+	app.asanaService.SetAccessToken(next.Asana.AccessToken)
+	app.rateLimiter.Update(next.Asana.RateLimit)
+	app.circuitBreaker.Update(next.CircuitBreaker)
+	app.dataDumper.Update(next.DataDumper)
+
+	for _, key := range restartRequiredChanges(prev, next) {
+		logger.Warn("configuration change requires a restart to take effect", zap.String("key", key))
+	}
+
+	logger.Info("configuration reloaded")
+}
+
+func restartRequiredChanges(prev, next config.Config) []string {
+	var keys []string
+
+	if prev.Http.Addr != next.Http.Addr {
+		keys = append(keys, "http.addr")
+	}
+
+	if !reflect.DeepEqual(prev.Http.RequestTimeout, next.Http.RequestTimeout) {
+		keys = append(keys, "http.request_timeout")
 	}
 
-	return err
+	if !prev.Http.LegacyRoutes.DeprecatedAt.Equal(next.Http.LegacyRoutes.DeprecatedAt) ||
+		!prev.Http.LegacyRoutes.Sunset.Equal(next.Http.LegacyRoutes.Sunset) {
+		keys = append(keys, "http.legacy_routes")
+	}
+
+	if !slices.Equal(prev.Logging.Output, next.Logging.Output) {
+		keys = append(keys, "logging.output")
+	}
+
+	if !reflect.DeepEqual(prev.Logging.Outputs, next.Logging.Outputs) {
+		keys = append(keys, "logging.outputs")
+	}
+
+	if !reflect.DeepEqual(prev.Logging.Sampling, next.Logging.Sampling) {
+		keys = append(keys, "logging.sampling")
+	}
+
+	if !reflect.DeepEqual(prev.Logging.Redaction, next.Logging.Redaction) {
+		keys = append(keys, "logging.redaction")
+	}
+
+	if prev.Logging.LogStackTrace != next.Logging.LogStackTrace {
+		keys = append(keys, "logging.log_stack_trace")
+	}
+
+	if prev.Asana.BaseURL != next.Asana.BaseURL {
+		keys = append(keys, "asana.base_url")
+	}
+
+	if prev.Asana.Transport != next.Asana.Transport {
+		keys = append(keys, "asana.transport")
+	}
+
+	if prev.Asana.Retry != next.Asana.Retry {
+		keys = append(keys, "asana.retry")
+	}
+
+	if !reflect.DeepEqual(prev.Asana.Cassette, next.Asana.Cassette) {
+		keys = append(keys, "asana.cassette")
+	}
+
+	if prev.Asana.Coalescing != next.Asana.Coalescing {
+		keys = append(keys, "asana.coalescing")
+	}
+
+	if !reflect.DeepEqual(prev.Asana.Hedging, next.Asana.Hedging) {
+		keys = append(keys, "asana.hedging")
+	}
+
+	if !reflect.DeepEqual(prev.Tracing, next.Tracing) {
+		keys = append(keys, "tracing")
+	}
+
+	if !reflect.DeepEqual(prev.Auth, next.Auth) {
+		keys = append(keys, "auth")
+	}
+
+	return keys
 }
 
-func (app *Application) Shutdown() {
+func (app *Application) Shutdown() error {
 	app.shutdownOnce.Do(func() {
 		defer func() {
-			err := logging.Logger.Sync()
-			if err != nil {
-				logging.Logger.Error("error calling logger.Sync()", zap.Error(err))
-			}
+			app.shutdownErr = multierr.Append(app.shutdownErr, logging.Close())
 		}()
 
-		c := make(chan struct{})
-		go func() {
-			app.stop()
-			close(c)
-		}()
+		cfg := app.Config()
+		shutdownTimeout := cfg.ShutdownTimeout
 
-		select {
-		case <-c:
-			logging.Logger.Info("application shutdown successfully complete")
-		case <-time.After(app.Config.ShutdownTimeout):
-			logging.Logger.Info("could not shutdown application in", zap.Duration("shutdown_timeout", app.Config.ShutdownTimeout))
+		// Only a server has traffic to move away before it drains.
+		var shutdownDelay time.Duration
+		if app.server != nil {
+			shutdownDelay = cfg.ShutdownDelay
 		}
-	})
-}
 
-func (app *Application) stop() {
-	ctx, cancel := context.WithTimeout(context.Background(), app.Config.ShutdownTimeout)
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownDelay+shutdownTimeout)
+		defer cancel()
+
+		app.shutdownErr = app.lifecycle.Stop(appcontext.WithLogger(ctx, logging.Logger), shutdownDelay)
+		if app.shutdownErr != nil {
+			logging.Logger.Error("application shutdown failed",
+				zap.Error(app.shutdownErr),
+				zap.Duration("shutdown_timeout", shutdownTimeout),
+			)
+			return
+		}
 
-	logging.WithLogger(ctx, logging.Logger)
+		logging.Logger.Info("application shutdown successfully complete")
+	})
 
-	defer cancel()
+	return app.shutdownErr
 }
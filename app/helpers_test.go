@@ -0,0 +1,25 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/cyber/test-project/asanatest"
+	"github.com/cyber/test-project/clients"
+	"github.com/cyber/test-project/config"
+	"github.com/cyber/test-project/services"
+)
+
+// newTestAsanaService returns a service calling the fake Asana API at baseURL and dumping into a
+// temporary directory.
+func newTestAsanaService(t *testing.T, baseURL string) *services.AsanaService {
+	t.Helper()
+
+	client := clients.NewAsanaClient(clients.ClientOptions{
+		ServiceName: "asana",
+		BaseURL:     baseURL,
+		Middlewares: []clients.Middleware{clients.UserAgent(userAgent), clients.Logging()},
+	})
+	dumper := services.NewAsanaDataDumper(config.DataDumperConfig{Path: t.TempDir()})
+
+	return services.NewAsanaService(client, config.AsanaConfig{AccessToken: asanatest.DefaultToken}, dumper)
+}
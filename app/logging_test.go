@@ -0,0 +1,69 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/cyber/test-project/appcontext"
+	"github.com/cyber/test-project/asanatest"
+	"github.com/cyber/test-project/logging"
+	"github.com/cyber/test-project/middleware"
+)
+
+func TestLogFieldsPropagateToClientAndDumper(t *testing.T) {
+	err := logging.SetComponentLevels(map[string]string{"clients": "debug", "services": "debug"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = logging.SetComponentLevels(nil) })
+
+	upstream := asanatest.NewServer(asanatest.DefaultFixtures())
+	t.Cleanup(upstream.Close)
+
+	router, err := NewRouter(RouterConfig{AsanaService: newTestAsanaService(t, upstream.URL)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The observer is the request logger that middleware.RequestID and everything after it build on.
+	core, logs := observer.New(zapcore.DebugLevel)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		router.ServeHTTP(w, r.WithContext(appcontext.WithLogger(r.Context(), zap.New(core))))
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/users/1201", nil)
+	request.Header.Set(middleware.RequestIDHeader, "req-034")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", recorder.Code, http.StatusOK, recorder.Body)
+	}
+
+	want := map[string]string{
+		"request_id": "req-034",
+		"service":    "asana",
+		"method":     http.MethodGet,
+		"path":       "/api/1.0/users/1201",
+	}
+	for _, message := range []string{"outbound request", "dumped resources"} {
+		entries := logs.FilterMessage(message).All()
+		if len(entries) == 0 {
+			t.Errorf("no %q log entry", message)
+			continue
+		}
+
+		for _, entry := range entries {
+			fields := entry.ContextMap()
+			for key, value := range want {
+				if fields[key] != value {
+					t.Errorf("%q entry has %s = %v, want %q", message, key, fields[key], value)
+				}
+			}
+		}
+	}
+}
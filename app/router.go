@@ -1,46 +1,319 @@
 package app
 
 import (
+	"errors"
+	"fmt"
+	"maps"
 	"net/http"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/justinas/alice"
 
+	"github.com/cyber/test-project/auth"
+	"github.com/cyber/test-project/config"
 	"github.com/cyber/test-project/controllers"
+	"github.com/cyber/test-project/metrics"
 	"github.com/cyber/test-project/middleware"
+	"github.com/cyber/test-project/models"
+	"github.com/cyber/test-project/openapi"
 	"github.com/cyber/test-project/services"
 	"github.com/cyber/test-project/transport"
+	"github.com/cyber/test-project/validation"
 )
 
 type AsanaService interface {
 	services.AsanaUsersGetter
+	services.AsanaUserGetter
 	services.AsanaProjectsGetter
+	services.AsanaProjectGetter
 }
 
 type RouterConfig struct {
-	AsanaService AsanaService
+	AsanaService   AsanaService
+	Readiness      controllers.ReadinessChecker
+	RequestTimeout config.RequestTimeoutConfig
+	LegacyRoutes   config.LegacyRoutesConfig
+	// Authenticator authenticates the callers of the API and admin routes, nil when authentication
+	// is disabled.
+	Authenticator *auth.Authenticator
 }
 
-const pathPrefix = "/api/"
+const (
+	pathPrefix       = "/api/"
+	healthPathPrefix = "/health/"
+	adminPathPrefix  = "/admin/"
+)
+
+// Scopes granted to API keys and JWTs.
+const (
+	scopeUsersRead    = "users:read"
+	scopeProjectsRead = "projects:read"
+	scopeAdminRead    = "admin:read"
+	scopeAdminWrite   = "admin:write"
+)
+
+var apiInfo = openapi.Info{
+	Title:       "test-project API",
+	Version:     "1.0.0",
+	Description: "Asana users and projects, fetched through the service and dumped to the data store.",
+}
+
+// apiSecuritySchemes documents the credentials accepted by the API, either of which grants the
+// scopes a route requires.
+var apiSecuritySchemes = map[string]openapi.SecurityScheme{
+	"apiKey":     {Type: "apiKey", In: "header", Name: auth.APIKeyHeader},
+	"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+}
+
+const apiV1 = "v1"
+
+// apiVersions are the supported API versions, oldest first.
+var apiVersions = []string{apiV1}
+
+// The verb-style routes of the unversioned API are kept as aliases of their v1 successors until the
+// configured sunset. legacyRoutes maps the deprecated paths to the path of their successor in v1.
+var legacyRoutes = []struct {
+	path, name, successor string
+}{
+	{path: "/users/get", name: "getUsers", successor: "/users"},
+	{path: "/projects/get", name: "getProjects", successor: "/projects"},
+}
+
+// apiRoute is a GET endpoint of one API version. It is registered under /api/<version><path> and,
+// with the version negotiated from the Accept header, under /api<path>.
+type apiRoute struct {
+	path    string
+	handler http.HandlerFunc
+	// scope is required from the caller when authentication is enabled.
+	scope string
+	docs  openapi.Route
+}
+
+func apiRoutes(cfg RouterConfig) map[string][]apiRoute {
+	return map[string][]apiRoute{
+		apiV1: {
+			{
+				path:    "/users",
+				handler: controllers.AsanaGetUsers(cfg.AsanaService),
+				scope:   scopeUsersRead,
+				docs: openapi.Route{
+					Name:     "listUsers",
+					Summary:  "List Asana users of a workspace or team",
+					Tags:     []string{"users"},
+					Query:    controllers.GetUsersQuery{},
+					Response: models.AsanaGetUsersResponse{},
+				},
+			},
+			{
+				path:    "/users/{gid}",
+				handler: controllers.AsanaGetUser(cfg.AsanaService),
+				scope:   scopeUsersRead,
+				docs: openapi.Route{
+					Name:     "getUser",
+					Summary:  "Get an Asana user",
+					Tags:     []string{"users"},
+					Params:   controllers.UserPath{},
+					Response: models.AsanaGetUserResponse{},
+				},
+			},
+			{
+				path:    "/projects",
+				handler: controllers.AsanaGetProjects(cfg.AsanaService),
+				scope:   scopeProjectsRead,
+				docs: openapi.Route{
+					Name:     "listProjects",
+					Summary:  "List Asana projects of a workspace or team",
+					Tags:     []string{"projects"},
+					Query:    controllers.GetProjectsQuery{},
+					Response: models.AsanaGetProjectsResponse{},
+				},
+			},
+			{
+				path:    "/projects/{gid}",
+				handler: controllers.AsanaGetProject(cfg.AsanaService),
+				scope:   scopeProjectsRead,
+				docs: openapi.Route{
+					Name:     "getProject",
+					Summary:  "Get an Asana project",
+					Tags:     []string{"projects"},
+					Params:   controllers.GidPath{},
+					Response: models.AsanaGetProjectResponse{},
+				},
+			},
+		},
+	}
+}
 
 func NewRouter(cfg RouterConfig) (*mux.Router, error) {
+	router, _, err := newRouter(cfg)
+
+	return router, err
+}
+
+// OpenAPIDocument builds the OpenAPI document served at /api/openapi.json with the default legacy
+// route dates, failing when it does not match the registered routes.
+func OpenAPIDocument() (openapi.Document, error) {
+	_, doc, err := newRouter(RouterConfig{LegacyRoutes: config.LegacyRoutesConfig{}.WithDefaults()})
+
+	return doc, err
+}
+
+func newRouter(cfg RouterConfig) (*mux.Router, openapi.Document, error) {
 	router := mux.NewRouter()
 
 	chain := alice.New(
+		middleware.RequestID,
+		middleware.Tracing,
+		middleware.AccessLog,
+		middleware.Metrics,
 		middleware.Recovery(transport.SendError),
+		middleware.Timeout(cfg.RequestTimeout, transport.SendErrorStatus),
 	)
 
+	router.
+		Path("/metrics").
+		Methods(http.MethodGet).
+		Handler(metrics.Handler())
+
+	healthRouter := router.PathPrefix(healthPathPrefix).Subrouter()
+
+	healthRouter.
+		Path("/live").
+		Methods(http.MethodGet).
+		Handler(chain.ThenFunc(controllers.HealthLive()))
+
+	healthRouter.
+		Path("/ready").
+		Methods(http.MethodGet).
+		Handler(chain.ThenFunc(controllers.HealthReady(cfg.Readiness)))
+
+	adminRouter := router.PathPrefix(adminPathPrefix).Subrouter()
+
+	adminRouter.
+		Path("/log-level").
+		Methods(http.MethodGet).
+		Handler(chain.Append(authorize(cfg, scopeAdminRead)).ThenFunc(controllers.AdminGetLogLevel()))
+
+	// Without authentication, only callers on the same host may change log levels.
+	setLogLevel := authorize(cfg, scopeAdminWrite)
+	if cfg.Authenticator == nil {
+		setLogLevel = middleware.LoopbackOnly(transport.SendErrorStatus)
+	}
+
+	adminRouter.
+		Path("/log-level").
+		Methods(http.MethodPut).
+		Handler(chain.Append(setLogLevel).ThenFunc(controllers.AdminSetLogLevel()))
+
 	baseRouter := router.PathPrefix(pathPrefix).Subrouter()
 
+	routes := apiRoutes(cfg)
+	if err := checkBindings(routes); err != nil {
+		return nil, openapi.Document{}, err
+	}
+
+	var docs []openapi.Route
+
+	// Registered first, since /users/{gid} would match them too.
+	for _, legacy := range legacyRoutes {
+		i := slices.IndexFunc(routes[apiV1], func(route apiRoute) bool { return route.path == legacy.successor })
+		route, successor := routes[apiV1][i], pathPrefix+apiV1+legacy.successor
+
+		baseRouter.
+			Path(legacy.path).
+			Methods(http.MethodGet).
+			Handler(chain.Append(authorize(cfg, route.scope), middleware.Deprecated(cfg.LegacyRoutes.DeprecatedAt, cfg.LegacyRoutes.Sunset, successor), middleware.APIVersion(apiV1)).Then(route.handler)).
+			Name(legacy.name)
+
+		routeDocs := route.docs
+		routeDocs.Name = legacy.name
+		routeDocs.Description = "Deprecated alias of " + successor + ", removed on " + cfg.LegacyRoutes.Sunset.Format(time.DateOnly) + "."
+		routeDocs.Deprecated = true
+		routeDocs.Scope = route.scope
+		docs = append(docs, routeDocs)
+	}
+
+	negotiated := map[string]map[string]http.Handler{}
+	negotiatedDocs := map[string]openapi.Route{}
+	// Every version of a path requires the same scope.
+	scopes := map[string]string{}
+	for _, version := range apiVersions {
+		for _, route := range routes[version] {
+			name := route.docs.Name + strings.ToUpper(version)
+
+			baseRouter.
+				Path("/" + version + route.path).
+				Methods(http.MethodGet).
+				Handler(chain.Append(authorize(cfg, route.scope), middleware.APIVersion(version)).Then(route.handler)).
+				Name(name)
+
+			routeDocs := route.docs
+			routeDocs.Name = name
+			routeDocs.Scope = route.scope
+			docs = append(docs, routeDocs)
+
+			if negotiated[route.path] == nil {
+				negotiated[route.path] = map[string]http.Handler{}
+			}
+			negotiated[route.path][version] = route.handler
+			negotiatedDocs[route.path] = route.docs
+			scopes[route.path] = route.scope
+		}
+	}
+
+	for _, path := range slices.Sorted(maps.Keys(negotiated)) {
+		baseRouter.
+			Path(path).
+			Methods(http.MethodGet).
+			Handler(chain.Append(authorize(cfg, scopes[path])).Then(middleware.NegotiateVersion(apiVersions, negotiated[path], transport.SendErrorStatus))).
+			Name(negotiatedDocs[path].Name)
+
+		routeDocs := negotiatedDocs[path]
+		routeDocs.Scope = scopes[path]
+		routeDocs.Description = "Serves the API version asked for with an Accept header like " +
+			middleware.VersionMediaType(apiV1) + ", or the latest one."
+		docs = append(docs, routeDocs)
+	}
+
+	// Every route registered above this point must be documented in docs.
+	doc, err := openapi.Build(router, apiInfo, pathPrefix, transport.ErrorResponse{}, docs, apiSecuritySchemes)
+	if err != nil {
+		return nil, openapi.Document{}, fmt.Errorf("openapi: %w", err)
+	}
+
 	baseRouter.
-		Path("/users/get").
+		Path("/openapi.json").
 		Methods(http.MethodGet).
-		Handler(chain.ThenFunc(controllers.AsanaGetUsers(cfg.AsanaService)))
+		Handler(chain.ThenFunc(openapi.Handler(doc)))
 
 	baseRouter.
-		Path("/projects/get").
+		Path("/docs").
 		Methods(http.MethodGet).
-		Handler(chain.ThenFunc(controllers.AsanaGetProjects(cfg.AsanaService)))
+		Handler(chain.ThenFunc(openapi.UIHandler(apiInfo.Title, pathPrefix+"openapi.json")))
+
+	return router, doc, nil
+}
+
+// checkBindings fails for request types whose tags would otherwise only be found broken by a request.
+func checkBindings(routes map[string][]apiRoute) error {
+	errs := []error{validation.Check(controllers.LogLevels{}, "json")}
+	for _, version := range apiVersions {
+		for _, route := range routes[version] {
+			if route.docs.Params != nil {
+				errs = append(errs, validation.Check(route.docs.Params, "path"))
+			}
+			if route.docs.Query != nil {
+				errs = append(errs, validation.Check(route.docs.Query, "query"))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
 
-	return router, nil
+func authorize(cfg RouterConfig, scope string) alice.Constructor {
+	return middleware.Authorize(cfg.Authenticator, scope, transport.SendErrorStatus)
 }
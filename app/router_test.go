@@ -0,0 +1,130 @@
+package app
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/cyber/test-project/openapi"
+)
+
+func TestRouterMatchesOpenAPIDocument(t *testing.T) {
+	router, err := NewRouter(RouterConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registered := map[string]bool{}
+	err = router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		// Path prefixes of subrouters match no method.
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		for _, method := range methods {
+			registered[method+" "+path] = true
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, pathPrefix+"openapi.json", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", recorder.Code, http.StatusOK, recorder.Body)
+	}
+
+	var doc openapi.Document
+	if err := json.Unmarshal(recorder.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	documented := map[string]*openapi.Operation{}
+	for path, item := range doc.Paths {
+		for method, operation := range item {
+			documented[strings.ToUpper(method)+" "+path] = operation
+		}
+	}
+
+	// Routes serving the document itself, or outside the API, are left out of it.
+	undocumented := []string{
+		"GET /api/openapi.json",
+		"GET /api/docs",
+		"GET /metrics",
+		"GET /health/live",
+		"GET /health/ready",
+		"GET /admin/log-level",
+		"PUT /admin/log-level",
+	}
+	for _, route := range undocumented {
+		if !registered[route] {
+			t.Errorf("%s is not registered", route)
+		}
+		if documented[route] != nil {
+			t.Errorf("%s is documented", route)
+		}
+	}
+
+	for _, route := range slices.Sorted(maps.Keys(registered)) {
+		if documented[route] == nil && !slices.Contains(undocumented, route) {
+			t.Errorf("%s is registered but not documented", route)
+		}
+	}
+	for _, route := range slices.Sorted(maps.Keys(documented)) {
+		if !registered[route] {
+			t.Errorf("%s is documented but not registered", route)
+		}
+	}
+
+	for _, legacy := range legacyRoutes {
+		operation := documented["GET "+strings.TrimSuffix(pathPrefix, "/")+legacy.path]
+		if operation == nil || !operation.Deprecated {
+			t.Errorf("legacy alias %s is not documented as deprecated: %+v", legacy.path, operation)
+		}
+	}
+}
+
+func TestSetLogLevelWithoutAuthOnlyFromLoopback(t *testing.T) {
+	router, err := NewRouter(RouterConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		wantStatus int
+	}{
+		{name: "loopback", remoteAddr: "127.0.0.1:52000", wantStatus: http.StatusOK},
+		{name: "loopback IPv6", remoteAddr: "[::1]:52000", wantStatus: http.StatusOK},
+		{name: "network", remoteAddr: "192.0.2.1:52000", wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// An empty request changes no level.
+			request := httptest.NewRequest(http.MethodPut, adminPathPrefix+"log-level", strings.NewReader(`{}`))
+			request.RemoteAddr = tt.remoteAddr
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", recorder.Code, tt.wantStatus, recorder.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+
+	"github.com/cyber/test-project/appcontext"
+	"github.com/cyber/test-project/logging"
+	"github.com/cyber/test-project/services"
+)
+
+// RunTask starts every component except the HTTP server, runs task and shuts everything down, so
+// one-shot commands share the server's wiring and cleanup.
+func (app *Application) RunTask(ctx context.Context, task func(context.Context) error) error {
+	startErr := app.lifecycle.Start(ctx)
+
+	var taskErr error
+	if startErr == nil {
+		taskErr = task(appcontext.WithLogger(ctx, logging.Logger))
+	}
+
+	stopErr := app.Shutdown()
+
+	return multierr.Combine(startErr, taskErr, stopErr)
+}
+
+// Dump crawls the requested resources once and writes them to the dump store.
+func (app *Application) Dump(ctx context.Context, request services.CrawlRequest) (services.CrawlSummary, error) {
+	var summary services.CrawlSummary
+
+	err := app.RunTask(ctx, func(ctx context.Context) error {
+		var err error
+		summary, err = app.asanaService.Crawl(ctx, request, app.asanaService.DumpPage)
+
+		return err
+	})
+
+	return summary, err
+}
+
+// Sync crawls the requested resources into the dump store every interval until ctx is cancelled.
+// A failed crawl is reported and retried on the next tick.
+func (app *Application) Sync(ctx context.Context, request services.CrawlRequest, interval time.Duration, report func(services.CrawlSummary, error)) error {
+	return app.RunTask(ctx, func(ctx context.Context) error {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			summary, err := app.asanaService.Crawl(ctx, request, app.asanaService.DumpPage)
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			if err != nil {
+				logging.Logger.Error("sync failed", zap.Error(err))
+			}
+			report(summary, err)
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// Export crawls the requested resources once and writes them to bulk files through exporter.
+func (app *Application) Export(ctx context.Context, request services.CrawlRequest, exporter *services.BulkExporter) (services.CrawlSummary, error) {
+	var summary services.CrawlSummary
+
+	err := app.RunTask(ctx, func(ctx context.Context) error {
+		var err error
+		summary, err = app.asanaService.Crawl(ctx, request, exporter.WritePage)
+
+		return errors.Join(err, exporter.Close())
+	})
+
+	return summary, err
+}
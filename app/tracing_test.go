@@ -0,0 +1,102 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/cyber/test-project/asanatest"
+	"github.com/cyber/test-project/config"
+	"github.com/cyber/test-project/tracing"
+)
+
+func TestTracingSpanTree(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	sampleRatio := 1.0
+	shutdown, err := tracing.Init(context.Background(), config.TracingConfig{
+		Exporter:    tracing.ExporterNone,
+		ServiceName: "test-project",
+		SampleRatio: &sampleRatio,
+	}, tracing.WithExporter(exporter))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = shutdown(context.Background()) })
+
+	var (
+		mu          sync.Mutex
+		traceparent string
+	)
+	fake := asanatest.NewFake(asanatest.DefaultFixtures())
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		traceparent = r.Header.Get("traceparent")
+		mu.Unlock()
+
+		fake.ServeHTTP(w, r)
+	}))
+	t.Cleanup(upstream.Close)
+
+	router, err := NewRouter(RouterConfig{AsanaService: newTestAsanaService(t, upstream.URL)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/users/1201", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", recorder.Code, http.StatusOK, recorder.Body)
+	}
+
+	spans := map[string]tracetest.SpanStub{}
+	for _, span := range exporter.GetSpans() {
+		spans[span.Name] = span
+	}
+
+	inbound, call, attempt, dump := spans["GET /api/v1/users/{gid}"], spans["asana GET /api/1.0/users/{gid}"], spans["HTTP GET"], spans["dump user"]
+	tree := []struct {
+		name         string
+		span, parent tracetest.SpanStub
+	}{
+		{name: "outbound call", span: call, parent: inbound},
+		{name: "attempt", span: attempt, parent: call},
+		{name: "dump", span: dump, parent: inbound},
+	}
+	if !inbound.SpanContext.IsValid() {
+		t.Fatalf("no inbound span, got spans %v", spanNames(exporter.GetSpans()))
+	}
+	for _, tt := range tree {
+		if !tt.span.SpanContext.IsValid() {
+			t.Errorf("no %s span, got spans %v", tt.name, spanNames(exporter.GetSpans()))
+			continue
+		}
+
+		if tt.span.SpanContext.TraceID() != inbound.SpanContext.TraceID() {
+			t.Errorf("%s span is in trace %s, want %s", tt.name, tt.span.SpanContext.TraceID(), inbound.SpanContext.TraceID())
+		}
+
+		if tt.span.Parent.SpanID() != tt.parent.SpanContext.SpanID() {
+			t.Errorf("%s span %q has parent %s, want %q %s", tt.name, tt.span.Name, tt.span.Parent.SpanID(), tt.parent.Name, tt.parent.SpanContext.SpanID())
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := "00-" + attempt.SpanContext.TraceID().String() + "-" + attempt.SpanContext.SpanID().String() + "-01"
+	if traceparent != want {
+		t.Errorf("upstream traceparent = %q, want %q", traceparent, want)
+	}
+}
+
+func spanNames(spans tracetest.SpanStubs) []string {
+	names := make([]string, len(spans))
+	for i, span := range spans {
+		names[i] = span.Name
+	}
+
+	return names
+}
@@ -0,0 +1,30 @@
+package appcontext
+
+import (
+	"context"
+	"time"
+)
+
+// WithBudget bounds ctx by the given time budget and remembers it, so that downstream code can
+// tell how much of the original budget is left. A tighter deadline already set on ctx wins.
+func WithBudget(ctx context.Context, total time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(ctx, total)
+
+	return context.WithValue(ctx, budgetKey, total), cancel
+}
+
+// RemainingBudget reports how much time is left before the context deadline.
+func RemainingBudget(ctx context.Context) (time.Duration, bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+
+	return time.Until(deadline), true
+}
+
+func TotalBudget(ctx context.Context) (time.Duration, bool) {
+	total, ok := ctx.Value(budgetKey).(time.Duration)
+
+	return total, ok
+}
@@ -0,0 +1,10 @@
+package appcontext
+
+type key uint8
+
+const (
+	loggerKey key = iota
+	requestIDKey
+	tokenKey
+	budgetKey
+)
@@ -0,0 +1,87 @@
+package appcontext
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestToken(t *testing.T) {
+	ctx := context.Background()
+	if token := Token(ctx); token != "" {
+		t.Errorf("Token() without a token = %q, want empty", token)
+	}
+
+	if token := Token(WithToken(ctx, "tenant-token")); token != "tenant-token" {
+		t.Errorf("Token() = %q, want tenant-token", token)
+	}
+}
+
+func TestTraceAndSpanID(t *testing.T) {
+	ctx := context.Background()
+	if TraceID(ctx) != "" || SpanID(ctx) != "" {
+		t.Errorf("IDs without a span = %q, %q, want empty", TraceID(ctx), SpanID(ctx))
+	}
+
+	provider := sdktrace.NewTracerProvider()
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+
+	ctx, span := provider.Tracer("appcontext_test").Start(ctx, "parent")
+	defer span.End()
+	ctx, child := provider.Tracer("appcontext_test").Start(ctx, "child")
+	defer child.End()
+
+	if got, want := TraceID(ctx), span.SpanContext().TraceID().String(); got != want {
+		t.Errorf("TraceID() = %q, want %q", got, want)
+	}
+	if got, want := SpanID(ctx), child.SpanContext().SpanID().String(); got != want {
+		t.Errorf("SpanID() = %q, want the active span %q", got, want)
+	}
+
+	remote := trace.ContextWithRemoteSpanContext(context.Background(), span.SpanContext())
+	if TraceID(remote) != span.SpanContext().TraceID().String() {
+		t.Errorf("TraceID() of a remote parent = %q", TraceID(remote))
+	}
+}
+
+func TestBudget(t *testing.T) {
+	tests := []struct {
+		name          string
+		parentTimeout time.Duration
+		budget        time.Duration
+		wantRemaining time.Duration
+	}{
+		{name: "budget", budget: time.Minute, wantRemaining: time.Minute},
+		{name: "tighter parent deadline wins", parentTimeout: time.Second, budget: time.Minute, wantRemaining: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if _, ok := RemainingBudget(ctx); ok {
+				t.Fatal("RemainingBudget() without a deadline reported one")
+			}
+			if tt.parentTimeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.parentTimeout)
+				defer cancel()
+			}
+
+			ctx, cancel := WithBudget(ctx, tt.budget)
+			defer cancel()
+
+			total, ok := TotalBudget(ctx)
+			if !ok || total != tt.budget {
+				t.Errorf("TotalBudget() = %s, %t, want %s", total, ok, tt.budget)
+			}
+
+			remaining, ok := RemainingBudget(ctx)
+			if !ok || remaining > tt.wantRemaining || remaining < tt.wantRemaining-time.Second/2 {
+				t.Errorf("RemainingBudget() = %s, %t, want about %s", remaining, ok, tt.wantRemaining)
+			}
+		})
+	}
+}
@@ -8,10 +8,6 @@ import (
 	"github.com/cyber/test-project/logging"
 )
 
-type key uint8
-
-const loggerKey key = iota
-
 func Logger(ctx context.Context) *zap.Logger {
 	ctxLogger, ok := ctx.Value(loggerKey).(*zap.Logger)
 	if !ok {
@@ -0,0 +1,47 @@
+package appcontext
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+
+	return requestID
+}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// Token returns the upstream access token bound to the request, e.g. for a specific tenant.
+// Services fall back to their configured token when it is empty.
+func Token(ctx context.Context) string {
+	token, _ := ctx.Value(tokenKey).(string)
+
+	return token
+}
+
+func WithToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, tokenKey, token)
+}
+
+func TraceID(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.HasTraceID() {
+		return ""
+	}
+
+	return spanContext.TraceID().String()
+}
+
+func SpanID(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.HasSpanID() {
+		return ""
+	}
+
+	return spanContext.SpanID().String()
+}
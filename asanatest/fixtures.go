@@ -0,0 +1,77 @@
+package asanatest
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+)
+
+//go:embed fixtures/*.json
+var defaultFixtures embed.FS
+
+// Resource is an Asana resource as returned by the API. Fixtures are kept as plain JSON objects so
+// they can carry fields the models don't decode, e.g. a project's workspace.
+type Resource map[string]any
+
+func (r Resource) Gid() string {
+	gid, _ := r["gid"].(string)
+	return gid
+}
+
+// Fixtures holds the data served by the fake. Users and projects are served in slice order.
+type Fixtures struct {
+	Users    []Resource
+	Projects []Resource
+}
+
+// DefaultFixtures returns the built-in data set: two workspaces with a handful of users and projects.
+func DefaultFixtures() Fixtures {
+	fixtures, err := loadFixtures(defaultFixtures, "fixtures")
+	if err != nil {
+		panic(fmt.Sprintf("asanatest: broken built-in fixtures: %v", err))
+	}
+
+	return fixtures
+}
+
+// LoadFixtures reads `users.json` and `projects.json` from dir. Each holds a JSON array of
+// resources, the same layout `export -format json` writes. A missing file means no resources.
+func LoadFixtures(dir string) (Fixtures, error) {
+	return loadFixtures(os.DirFS(dir), ".")
+}
+
+func loadFixtures(fsys fs.FS, dir string) (Fixtures, error) {
+	users, err := loadResources(fsys, path.Join(dir, "users.json"))
+	if err != nil {
+		return Fixtures{}, err
+	}
+
+	projects, err := loadResources(fsys, path.Join(dir, "projects.json"))
+	if err != nil {
+		return Fixtures{}, err
+	}
+
+	return Fixtures{Users: users, Projects: projects}, nil
+}
+
+func loadResources(fsys fs.FS, name string) ([]Resource, error) {
+	encoded, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var resources []Resource
+	err = json.Unmarshal(encoded, &resources)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return resources, nil
+}
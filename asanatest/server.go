@@ -0,0 +1,328 @@
+// Package asanatest provides a fake Asana API for tests and local development. It serves users and
+// projects from fixtures with Asana's pagination, auth and error formats, and can inject faults such
+// as 429 responses with Retry-After or 5xx errors.
+package asanatest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// DefaultToken is the access token accepted unless WithToken is used.
+	DefaultToken = "asanatest-token"
+
+	UsersPath    = "/api/1.0/users"
+	MePath       = "/api/1.0/users/me"
+	ProjectsPath = "/api/1.0/projects"
+
+	maxLimit = 100
+)
+
+// Fault replaces the regular response of a request.
+type Fault struct {
+	Status int
+	// RetryAfter is sent as the Retry-After header, rounded up to whole seconds, when set.
+	RetryAfter time.Duration
+	// Times is how many requests the fault applies to when injected with InjectFault; 0 means once.
+	Times int
+}
+
+func RateLimited(retryAfter time.Duration) Fault {
+	return Fault{Status: http.StatusTooManyRequests, RetryAfter: retryAfter}
+}
+
+func ServerError(status int) Fault {
+	return Fault{Status: status}
+}
+
+type Option func(*Fake)
+
+// WithToken sets the only access token the fake accepts.
+func WithToken(token string) Option {
+	return func(f *Fake) {
+		f.token = token
+	}
+}
+
+// WithFaultEvery answers every n-th request with fault, for exercising retries and circuit breakers
+// against a long-running fake.
+func WithFaultEvery(n int, fault Fault) Option {
+	return func(f *Fake) {
+		if n > 0 {
+			f.periodic = append(f.periodic, periodicFault{every: n, fault: fault})
+		}
+	}
+}
+
+type periodicFault struct {
+	every int
+	fault Fault
+}
+
+// Fake is an http.Handler implementing the subset of the Asana API the service uses.
+type Fake struct {
+	router   *mux.Router
+	fixtures Fixtures
+	token    string
+	periodic []periodicFault
+
+	mu       sync.Mutex
+	faults   map[string][]Fault
+	requests map[string]int
+	total    int
+}
+
+func NewFake(fixtures Fixtures, opts ...Option) *Fake {
+	f := &Fake{
+		fixtures: fixtures,
+		token:    DefaultToken,
+		faults:   map[string][]Fault{},
+		requests: map[string]int{},
+	}
+
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	f.router = mux.NewRouter()
+	f.router.HandleFunc(MePath, f.getMe).Methods(http.MethodGet)
+	f.router.HandleFunc(UsersPath, f.listHandler(f.fixtures.Users, filterUsers)).Methods(http.MethodGet)
+	f.router.HandleFunc(UsersPath+"/{gid}", f.getHandler(f.fixtures.Users)).Methods(http.MethodGet)
+	f.router.HandleFunc(ProjectsPath, f.listHandler(f.fixtures.Projects, filterProjects)).Methods(http.MethodGet)
+	f.router.HandleFunc(ProjectsPath+"/{gid}", f.getHandler(f.fixtures.Projects)).Methods(http.MethodGet)
+	f.router.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sendErrors(w, http.StatusNotFound, "Unknown path "+r.URL.Path)
+	})
+
+	return f
+}
+
+// InjectFault makes the next fault.Times requests to path fail with fault. Faults for the same path
+// are served in the order they were injected.
+func (f *Fake) InjectFault(path string, fault Fault) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	times := fault.Times
+	if times <= 0 {
+		times = 1
+	}
+
+	for i := 0; i < times; i++ {
+		f.faults[path] = append(f.faults[path], fault)
+	}
+}
+
+// Requests returns how many requests were received for path, including failed ones.
+func (f *Fake) Requests(path string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.requests[path]
+}
+
+func (f *Fake) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fault, ok := f.nextFault(r.URL.Path)
+	if ok {
+		sendFault(w, fault)
+		return
+	}
+
+	if r.Header.Get("Authorization") != "Bearer "+f.token {
+		sendErrors(w, http.StatusUnauthorized, "Not Authorized")
+		return
+	}
+
+	f.router.ServeHTTP(w, r)
+}
+
+func (f *Fake) nextFault(path string) (Fault, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.requests[path]++
+	f.total++
+
+	if queued := f.faults[path]; len(queued) > 0 {
+		f.faults[path] = queued[1:]
+		return queued[0], true
+	}
+
+	for _, periodic := range f.periodic {
+		if f.total%periodic.every == 0 {
+			return periodic.fault, true
+		}
+	}
+
+	return Fault{}, false
+}
+
+func (f *Fake) getMe(w http.ResponseWriter, _ *http.Request) {
+	if len(f.fixtures.Users) == 0 {
+		sendErrors(w, http.StatusNotFound, "user: Not Found")
+		return
+	}
+
+	sendJson(w, http.StatusOK, map[string]any{"data": f.fixtures.Users[0]})
+}
+
+func (f *Fake) getHandler(resources []Resource) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		gid := mux.Vars(r)["gid"]
+		for _, resource := range resources {
+			// Users can also be looked up by email, like in Asana.
+			if resource.Gid() == gid || resource["email"] == gid {
+				sendJson(w, http.StatusOK, map[string]any{"data": resource})
+				return
+			}
+		}
+
+		sendErrors(w, http.StatusNotFound, gid+": Not Found")
+	}
+}
+
+type filterFunc func(resource Resource, query url.Values) bool
+
+func (f *Fake) listHandler(resources []Resource, filter filterFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		limit := maxLimit
+		if raw := query.Get("limit"); raw != "" {
+			parsed, err := strconv.Atoi(raw)
+			if err != nil || parsed < 1 || parsed > maxLimit {
+				sendErrors(w, http.StatusBadRequest, "limit: Must be between 1 and 100")
+				return
+			}
+			limit = parsed
+		}
+
+		var matched []Resource
+		for _, resource := range resources {
+			if filter(resource, query) {
+				matched = append(matched, resource)
+			}
+		}
+
+		start := 0
+		if raw := query.Get("offset"); raw != "" {
+			parsed, err := strconv.Atoi(raw)
+			if err != nil || parsed < 0 || parsed > len(matched) {
+				sendErrors(w, http.StatusBadRequest, "offset: Your pagination token is invalid.")
+				return
+			}
+			start = parsed
+		}
+
+		end := min(start+limit, len(matched))
+		page := matched[start:end]
+		if page == nil {
+			page = []Resource{}
+		}
+
+		var nextPage any
+		if end < len(matched) {
+			next := url.Values{}
+			for key, values := range query {
+				next[key] = values
+			}
+			next.Set("limit", strconv.Itoa(limit))
+			next.Set("offset", strconv.Itoa(end))
+
+			path := strings.TrimPrefix(r.URL.Path, "/api/1.0") + "?" + next.Encode()
+			nextPage = map[string]string{
+				"offset": strconv.Itoa(end),
+				"path":   path,
+				"uri":    "http://" + r.Host + "/api/1.0" + path,
+			}
+		}
+
+		sendJson(w, http.StatusOK, map[string]any{"data": page, "next_page": nextPage})
+	}
+}
+
+func filterUsers(resource Resource, query url.Values) bool {
+	return matchesRef(resource["workspaces"], query.Get("workspace")) &&
+		matchesRef(resource["teams"], query.Get("team"))
+}
+
+func filterProjects(resource Resource, query url.Values) bool {
+	if archived := query.Get("archived"); archived != "" {
+		value, _ := resource["archived"].(bool)
+		if strconv.FormatBool(value) != archived {
+			return false
+		}
+	}
+
+	return matchesRef(resource["workspace"], query.Get("workspace")) &&
+		matchesRef(resource["team"], query.Get("team"))
+}
+
+// matchesRef reports whether ref, a compact resource or an array of them, has the given gid.
+// An empty gid matches everything.
+func matchesRef(ref any, gid string) bool {
+	if gid == "" {
+		return true
+	}
+
+	switch v := ref.(type) {
+	case map[string]any:
+		return v["gid"] == gid
+	case []any:
+		for _, element := range v {
+			if matchesRef(element, gid) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func sendFault(w http.ResponseWriter, fault Fault) {
+	if fault.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int((fault.RetryAfter+time.Second-1)/time.Second)))
+	}
+
+	sendErrors(w, fault.Status, http.StatusText(fault.Status))
+}
+
+func sendErrors(w http.ResponseWriter, status int, message string) {
+	sendJson(w, status, map[string]any{
+		"errors": []map[string]string{{"message": message}},
+	})
+}
+
+func sendJson(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(body)
+}
+
+// Server is a Fake listening on a local httptest.Server. Point asana.base_url or
+// clients.ClientOptions.BaseURL at Server.URL.
+type Server struct {
+	*httptest.Server
+	*Fake
+}
+
+func NewServer(fixtures Fixtures, opts ...Option) *Server {
+	fake := NewFake(fixtures, opts...)
+
+	return &Server{
+		Server: httptest.NewServer(fake),
+		Fake:   fake,
+	}
+}
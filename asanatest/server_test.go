@@ -0,0 +1,325 @@
+package asanatest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// page is a response of the fake; data is a list or a single resource.
+type page struct {
+	Data     json.RawMessage `json:"data"`
+	NextPage *struct {
+		Offset string `json:"offset"`
+		Path   string `json:"path"`
+		Uri    string `json:"uri"`
+	} `json:"next_page"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func get(t *testing.T, handler http.Handler, target, token string) (*httptest.ResponseRecorder, page) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	var body page
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decoding %s: %v", recorder.Body, err)
+	}
+
+	return recorder, body
+}
+
+func (p page) gids(t *testing.T) []string {
+	t.Helper()
+
+	if len(p.Data) == 0 {
+		return nil
+	}
+
+	var resources []Resource
+	if p.Data[0] == '[' {
+		if err := json.Unmarshal(p.Data, &resources); err != nil {
+			t.Fatal(err)
+		}
+	} else {
+		var resource Resource
+		if err := json.Unmarshal(p.Data, &resource); err != nil {
+			t.Fatal(err)
+		}
+		resources = []Resource{resource}
+	}
+
+	return gids(resources)
+}
+
+func gids(resources []Resource) []string {
+	var result []string
+	for _, resource := range resources {
+		result = append(result, resource.Gid())
+	}
+
+	return result
+}
+
+func TestFakeResponses(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		token      string
+		wantStatus int
+		wantGids   []string
+		wantNext   string
+		wantError  string
+	}{
+		{
+			name:       "first page",
+			target:     UsersPath + "?workspace=1000&limit=2",
+			wantStatus: http.StatusOK,
+			wantGids:   []string{"1201", "1202"},
+			wantNext:   "/users?limit=2&offset=2&workspace=1000",
+		},
+		{
+			name:       "last page",
+			target:     UsersPath + "?workspace=1000&limit=2&offset=4",
+			wantStatus: http.StatusOK,
+			wantGids:   []string{"1205"},
+		},
+		{
+			name:       "filtered by workspace",
+			target:     UsersPath + "?workspace=2000",
+			wantStatus: http.StatusOK,
+			wantGids:   []string{"1205", "1206", "1207"},
+		},
+		{
+			name:       "filtered by archived",
+			target:     ProjectsPath + "?workspace=1000&archived=true",
+			wantStatus: http.StatusOK,
+			wantGids:   []string{"3304"},
+		},
+		{
+			name:       "single resource",
+			target:     ProjectsPath + "/3305",
+			wantStatus: http.StatusOK,
+			wantGids:   []string{"3305"},
+		},
+		{
+			name:       "user by email",
+			target:     UsersPath + "/grace@example.com",
+			wantStatus: http.StatusOK,
+			wantGids:   []string{"1203"},
+		},
+		{
+			name:       "me",
+			target:     MePath,
+			wantStatus: http.StatusOK,
+			wantGids:   []string{"1201"},
+		},
+		{
+			name:       "unknown gid",
+			target:     UsersPath + "/404",
+			wantStatus: http.StatusNotFound,
+			wantError:  "404: Not Found",
+		},
+		{
+			name:       "wrong token",
+			target:     UsersPath,
+			token:      "wrong",
+			wantStatus: http.StatusUnauthorized,
+			wantError:  "Not Authorized",
+		},
+		{
+			name:       "limit out of range",
+			target:     UsersPath + "?limit=101",
+			wantStatus: http.StatusBadRequest,
+			wantError:  "limit: Must be between 1 and 100",
+		},
+		{
+			name:       "invalid offset",
+			target:     UsersPath + "?offset=next",
+			wantStatus: http.StatusBadRequest,
+			wantError:  "offset: Your pagination token is invalid.",
+		},
+	}
+
+	fake := NewFake(DefaultFixtures())
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := tt.token
+			if token == "" {
+				token = DefaultToken
+			}
+
+			recorder, body := get(t, fake, tt.target, token)
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, tt.wantStatus, recorder.Body)
+			}
+
+			if got := body.gids(t); !slices.Equal(got, tt.wantGids) {
+				t.Errorf("gids = %v, want %v", got, tt.wantGids)
+			}
+
+			next := ""
+			if body.NextPage != nil {
+				next = body.NextPage.Path
+			}
+			if next != tt.wantNext {
+				t.Errorf("next_page.path = %q, want %q", next, tt.wantNext)
+			}
+
+			if tt.wantError != "" && (len(body.Errors) != 1 || body.Errors[0].Message != tt.wantError) {
+				t.Errorf("errors = %+v, want %q", body.Errors, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestServerFollowsNextPage(t *testing.T) {
+	server := NewServer(DefaultFixtures())
+	defer server.Close()
+
+	var all []string
+	uri := server.URL + UsersPath + "?limit=3"
+	for pages := 0; uri != ""; pages++ {
+		if pages > 10 {
+			t.Fatal("next_page does not terminate")
+		}
+
+		req, err := http.NewRequest(http.MethodGet, uri, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+DefaultToken)
+
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var body page
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		_ = resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		all = append(all, body.gids(t)...)
+
+		uri = ""
+		if body.NextPage != nil {
+			uri = body.NextPage.Uri
+		}
+	}
+
+	if want := gids(DefaultFixtures().Users); !slices.Equal(all, want) {
+		t.Errorf("gids = %v, want %v", all, want)
+	}
+}
+
+func TestFakeFaults(t *testing.T) {
+	tests := []struct {
+		name           string
+		inject         func(*Fake)
+		opts           []Option
+		wantStatuses   []int
+		wantRetryAfter string
+	}{
+		{
+			name:           "rate limited",
+			inject:         func(f *Fake) { f.InjectFault(UsersPath, RateLimited(1500*time.Millisecond)) },
+			wantStatuses:   []int{http.StatusTooManyRequests, http.StatusOK},
+			wantRetryAfter: "2",
+		},
+		{
+			name: "server error twice",
+			inject: func(f *Fake) {
+				f.InjectFault(UsersPath, Fault{Status: http.StatusBadGateway, Times: 2})
+			},
+			wantStatuses: []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusOK},
+		},
+		{
+			name: "faults in injection order",
+			inject: func(f *Fake) {
+				f.InjectFault(UsersPath, ServerError(http.StatusServiceUnavailable))
+				f.InjectFault(UsersPath, RateLimited(time.Second))
+			},
+			wantStatuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK},
+		},
+		{
+			name:         "every third request",
+			opts:         []Option{WithFaultEvery(3, ServerError(http.StatusInternalServerError))},
+			wantStatuses: []int{http.StatusOK, http.StatusOK, http.StatusInternalServerError, http.StatusOK},
+		},
+		{
+			name:         "custom token",
+			opts:         []Option{WithToken("other")},
+			wantStatuses: []int{http.StatusUnauthorized},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := NewFake(DefaultFixtures(), tt.opts...)
+			if tt.inject != nil {
+				tt.inject(fake)
+			}
+
+			for i, want := range tt.wantStatuses {
+				recorder, _ := get(t, fake, UsersPath, DefaultToken)
+				if recorder.Code != want {
+					t.Errorf("request %d: status = %d, want %d", i+1, recorder.Code, want)
+				}
+				if want == http.StatusTooManyRequests && tt.wantRetryAfter != "" {
+					if got := recorder.Header().Get("Retry-After"); got != tt.wantRetryAfter {
+						t.Errorf("Retry-After = %q, want %q", got, tt.wantRetryAfter)
+					}
+				}
+			}
+
+			if got := fake.Requests(UsersPath); got != len(tt.wantStatuses) {
+				t.Errorf("Requests() = %d, want %d", got, len(tt.wantStatuses))
+			}
+		})
+	}
+}
+
+func TestLoadFixtures(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "users.json"), []byte(`[{"gid": "1", "name": "Only"}]`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixtures, err := LoadFixtures(dir)
+	if err != nil {
+		t.Fatalf("LoadFixtures() error = %v", err)
+	}
+
+	if !slices.Equal(gids(fixtures.Users), []string{"1"}) || len(fixtures.Projects) != 0 {
+		t.Errorf("fixtures = %+v, want one user and no projects", fixtures)
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "projects.json"), []byte(`{}`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadFixtures(dir); err == nil {
+		t.Error("LoadFixtures() with a broken file succeeded")
+	}
+}
@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+
+	"github.com/cyber/test-project/config"
+)
+
+const hashPrefix = "sha256:"
+
+// HashAPIKey returns the hash of key in the form stored in auth.api_keys[].hash.
+func HashAPIKey(key string) string {
+	digest := sha256.Sum256([]byte(key))
+
+	return hashPrefix + hex.EncodeToString(digest[:])
+}
+
+type apiKeys struct {
+	keys []config.APIKeyConfig
+}
+
+func newAPIKeys(keys []config.APIKeyConfig) *apiKeys {
+	return &apiKeys{keys: keys}
+}
+
+// verify compares the hash of key with every configured hash in constant time. Keys are random
+// secrets, so a fast hash does not make them easier to guess.
+func (k *apiKeys) verify(key string) (Principal, error) {
+	hash := []byte(HashAPIKey(key))
+
+	match := -1
+	for i, apiKey := range k.keys {
+		if subtle.ConstantTimeCompare(hash, []byte(strings.ToLower(apiKey.Hash))) == 1 {
+			match = i
+		}
+	}
+
+	if match < 0 {
+		return Principal{}, ErrInvalidAPIKey
+	}
+
+	return Principal{
+		Subject: k.keys[match].Name,
+		Method:  MethodAPIKey,
+		Scopes:  k.keys[match].Scopes,
+	}, nil
+}
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/cyber/test-project/config"
+)
+
+// APIKeyHeader carries a static API key.
+const APIKeyHeader = "X-API-Key"
+
+const (
+	MethodAPIKey = "api_key"
+	MethodJWT    = "jwt"
+)
+
+var (
+	ErrMissingCredentials = errors.New("missing credentials, send an API key in the " + APIKeyHeader + " header or a JWT in the Authorization header")
+	ErrInvalidAPIKey      = errors.New("invalid API key")
+	ErrJWTDisabled        = errors.New("JWTs are not accepted")
+)
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	// Subject is the name of the API key or the sub claim of the token.
+	Subject string
+	Method  string
+	Scopes  []string
+}
+
+func (p Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, scope)
+}
+
+// Authenticator checks the credentials of inbound requests against the configured API keys and
+// JWKS.
+type Authenticator struct {
+	apiKeys *apiKeys
+	jwt     *jwtVerifier
+}
+
+func NewAuthenticator(cfg config.AuthConfig) (*Authenticator, error) {
+	authenticator := &Authenticator{apiKeys: newAPIKeys(cfg.APIKeys)}
+
+	if cfg.JWT.JWKSFile != "" {
+		verifier, err := newJWTVerifier(cfg.JWT)
+		if err != nil {
+			return nil, err
+		}
+		authenticator.jwt = verifier
+	}
+
+	return authenticator, nil
+}
+
+// Authenticate returns the caller of r, identified by the API key header or else the bearer token.
+func (a *Authenticator) Authenticate(r *http.Request) (Principal, error) {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		return a.apiKeys.verify(key)
+	}
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return Principal{}, ErrMissingCredentials
+	}
+
+	if a.jwt == nil {
+		return Principal{}, ErrJWTDisabled
+	}
+
+	return a.jwt.verify(token)
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// FromContext returns the authenticated caller of the request, if any.
+func FromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+
+	return principal, ok
+}
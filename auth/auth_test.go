@@ -0,0 +1,358 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/cyber/test-project/config"
+)
+
+var (
+	rsaKeysOnce sync.Once
+	rsaKeys     [2]*rsa.PrivateKey
+)
+
+// testRSAKeys returns two RSA keys, generated once for all tests.
+func testRSAKeys(t *testing.T) [2]*rsa.PrivateKey {
+	t.Helper()
+
+	rsaKeysOnce.Do(func() {
+		for i := range rsaKeys {
+			key, err := rsa.GenerateKey(rand.Reader, 2048)
+			if err != nil {
+				panic(err)
+			}
+			rsaKeys[i] = key
+		}
+	})
+
+	return rsaKeys
+}
+
+func rsaJWK(kid string, key *rsa.PrivateKey) jwk {
+	return jwk{
+		Kty: "RSA",
+		Kid: kid,
+		Use: "sig",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func writeJWKS(t *testing.T, keys ...jwk) string {
+	t.Helper()
+
+	data, err := json.Marshal(map[string][]jwk{"keys": keys})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key any, tokenClaims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, tokenClaims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signed
+}
+
+func TestJWTVerify(t *testing.T) {
+	keys := testRSAKeys(t)
+	secret := []byte("hmac-secret-of-32-bytes-at-least")
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: must(x509.MarshalPKIXPublicKey(&keys[0].PublicKey))})
+
+	now := time.Now()
+	valid := func(extra jwt.MapClaims) jwt.MapClaims {
+		tokenClaims := jwt.MapClaims{
+			"sub":   "dashboard",
+			"iss":   "https://issuer.example.com",
+			"aud":   "test-project",
+			"exp":   now.Add(time.Hour).Unix(),
+			"scope": "users:read projects:read",
+		}
+		for name, value := range extra {
+			if value == nil {
+				delete(tokenClaims, name)
+				continue
+			}
+			tokenClaims[name] = value
+		}
+		return tokenClaims
+	}
+
+	rsaOnly := config.JWTConfig{
+		JWKSFile:   writeJWKS(t, rsaJWK("key-1", keys[0]), rsaJWK("key-2", keys[1])),
+		Algorithms: []string{config.JWTAlgorithmRS256},
+		Issuer:     "https://issuer.example.com",
+		Audience:   "test-project",
+	}
+	singleKey := rsaOnly
+	singleKey.JWKSFile = writeJWKS(t, rsaJWK("key-1", keys[0]))
+	withHMAC := rsaOnly
+	withHMAC.JWKSFile = writeJWKS(t, rsaJWK("key-1", keys[0]), jwk{Kty: "oct", Kid: "hmac", K: base64.RawURLEncoding.EncodeToString(secret)})
+	withHMAC.Algorithms = []string{config.JWTAlgorithmRS256, config.JWTAlgorithmHS256}
+	withLeeway := rsaOnly
+	withLeeway.Leeway = time.Minute
+
+	tests := []struct {
+		name       string
+		cfg        config.JWTConfig
+		token      string
+		wantScopes []string
+		wantErr    bool
+	}{
+		{
+			name:       "RS256 with kid",
+			cfg:        rsaOnly,
+			token:      sign(t, jwt.SigningMethodRS256, "key-2", keys[1], valid(nil)),
+			wantScopes: []string{"users:read", "projects:read"},
+		},
+		{
+			name:       "scp array",
+			cfg:        rsaOnly,
+			token:      sign(t, jwt.SigningMethodRS256, "key-1", keys[0], valid(jwt.MapClaims{"scope": nil, "scp": []string{"admin:read"}})),
+			wantScopes: []string{"admin:read"},
+		},
+		{
+			name:       "scope and scp",
+			cfg:        rsaOnly,
+			token:      sign(t, jwt.SigningMethodRS256, "key-1", keys[0], valid(jwt.MapClaims{"scope": "users:read", "scp": []string{"admin:read"}})),
+			wantScopes: []string{"users:read", "admin:read"},
+		},
+		{
+			name:    "kid of another key",
+			cfg:     rsaOnly,
+			token:   sign(t, jwt.SigningMethodRS256, "key-2", keys[0], valid(nil)),
+			wantErr: true,
+		},
+		{
+			name:    "unknown kid",
+			cfg:     rsaOnly,
+			token:   sign(t, jwt.SigningMethodRS256, "key-3", keys[0], valid(nil)),
+			wantErr: true,
+		},
+		{
+			name:       "no kid with a single key",
+			cfg:        singleKey,
+			token:      sign(t, jwt.SigningMethodRS256, "", keys[0], valid(nil)),
+			wantScopes: []string{"users:read", "projects:read"},
+		},
+		{
+			name:    "no kid with several keys",
+			cfg:     rsaOnly,
+			token:   sign(t, jwt.SigningMethodRS256, "", keys[0], valid(nil)),
+			wantErr: true,
+		},
+		{
+			name:    "alg none",
+			cfg:     rsaOnly,
+			token:   sign(t, jwt.SigningMethodNone, "key-1", jwt.UnsafeAllowNoneSignatureType, valid(nil)),
+			wantErr: true,
+		},
+		{
+			name:    "HS256 signed with the RSA public key",
+			cfg:     rsaOnly,
+			token:   sign(t, jwt.SigningMethodHS256, "key-1", publicPEM, valid(nil)),
+			wantErr: true,
+		},
+		{
+			name:    "HS256 not allowed",
+			cfg:     func() config.JWTConfig { cfg := withHMAC; cfg.Algorithms = rsaOnly.Algorithms; return cfg }(),
+			token:   sign(t, jwt.SigningMethodHS256, "hmac", secret, valid(nil)),
+			wantErr: true,
+		},
+		{
+			name:       "HS256 allowed",
+			cfg:        withHMAC,
+			token:      sign(t, jwt.SigningMethodHS256, "hmac", secret, valid(nil)),
+			wantScopes: []string{"users:read", "projects:read"},
+		},
+		{
+			name:    "HS256 with the kid of an RSA key",
+			cfg:     withHMAC,
+			token:   sign(t, jwt.SigningMethodHS256, "key-1", publicPEM, valid(nil)),
+			wantErr: true,
+		},
+		{
+			name:    "missing exp",
+			cfg:     rsaOnly,
+			token:   sign(t, jwt.SigningMethodRS256, "key-1", keys[0], valid(jwt.MapClaims{"exp": nil})),
+			wantErr: true,
+		},
+		{
+			name:    "expired",
+			cfg:     rsaOnly,
+			token:   sign(t, jwt.SigningMethodRS256, "key-1", keys[0], valid(jwt.MapClaims{"exp": now.Add(-time.Second).Unix()})),
+			wantErr: true,
+		},
+		{
+			name:       "expired within leeway",
+			cfg:        withLeeway,
+			token:      sign(t, jwt.SigningMethodRS256, "key-1", keys[0], valid(jwt.MapClaims{"exp": now.Add(-time.Second).Unix()})),
+			wantScopes: []string{"users:read", "projects:read"},
+		},
+		{
+			name:    "other issuer",
+			cfg:     rsaOnly,
+			token:   sign(t, jwt.SigningMethodRS256, "key-1", keys[0], valid(jwt.MapClaims{"iss": "https://evil.example.com"})),
+			wantErr: true,
+		},
+		{
+			name:    "other audience",
+			cfg:     rsaOnly,
+			token:   sign(t, jwt.SigningMethodRS256, "key-1", keys[0], valid(jwt.MapClaims{"aud": "other-service"})),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier, err := newJWTVerifier(tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			principal, err := verifier.verify(tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("verify() error = %v, want error %t", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if principal.Subject != "dashboard" || principal.Method != MethodJWT || !slices.Equal(principal.Scopes, tt.wantScopes) {
+				t.Errorf("principal = %+v, want dashboard with scopes %v", principal, tt.wantScopes)
+			}
+		})
+	}
+}
+
+func TestAPIKeysVerify(t *testing.T) {
+	const key = "s3cr3t-api-key"
+
+	tests := []struct {
+		name        string
+		keys        []config.APIKeyConfig
+		key         string
+		wantSubject string
+		wantErr     error
+	}{
+		{
+			name:        "matching hash",
+			keys:        []config.APIKeyConfig{{Name: "other", Hash: HashAPIKey("other-key")}, {Name: "dashboard", Hash: HashAPIKey(key), Scopes: []string{"users:read"}}},
+			key:         key,
+			wantSubject: "dashboard",
+		},
+		{
+			name:        "upper case hash",
+			keys:        []config.APIKeyConfig{{Name: "dashboard", Hash: "sha256:" + strings.ToUpper(HashAPIKey(key)[len("sha256:"):])}},
+			key:         key,
+			wantSubject: "dashboard",
+		},
+		{
+			name:    "wrong key",
+			keys:    []config.APIKeyConfig{{Name: "dashboard", Hash: HashAPIKey(key)}},
+			key:     key + "x",
+			wantErr: ErrInvalidAPIKey,
+		},
+		{
+			name:    "configured hash used as the key",
+			keys:    []config.APIKeyConfig{{Name: "dashboard", Hash: HashAPIKey(key)}},
+			key:     HashAPIKey(key),
+			wantErr: ErrInvalidAPIKey,
+		},
+		{
+			name:    "no keys",
+			key:     key,
+			wantErr: ErrInvalidAPIKey,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := newAPIKeys(tt.keys).verify(tt.key)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("verify() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (principal.Subject != tt.wantSubject || principal.Method != MethodAPIKey) {
+				t.Errorf("principal = %+v, want %s by API key", principal, tt.wantSubject)
+			}
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	authenticator, err := NewAuthenticator(config.AuthConfig{
+		Enabled: true,
+		APIKeys: []config.APIKeyConfig{{Name: "dashboard", Hash: HashAPIKey("key")}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		wantErr error
+	}{
+		{name: "API key", headers: map[string]string{APIKeyHeader: "key"}},
+		{name: "API key wins over a bearer token", headers: map[string]string{APIKeyHeader: "key", "Authorization": "Bearer token"}},
+		{name: "no credentials", wantErr: ErrMissingCredentials},
+		{name: "empty bearer token", headers: map[string]string{"Authorization": "Bearer "}, wantErr: ErrMissingCredentials},
+		{name: "JWT without a JWKS", headers: map[string]string{"Authorization": "Bearer token"}, wantErr: ErrJWTDisabled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
+			for name, value := range tt.headers {
+				request.Header.Set(name, value)
+			}
+
+			_, err := authenticator.Authenticate(request)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Authenticate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func must[T any](v T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+
+	return v
+}
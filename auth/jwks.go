@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+// jwk is a key of a JSON Web Key Set (RFC 7517). Only RSA and symmetric ("oct") keys are used.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	K   string `json:"k"`
+}
+
+type jwks struct {
+	rsa map[string]*rsa.PublicKey
+	oct map[string][]byte
+}
+
+func loadJWKS(path string) (*jwks, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	err = json.Unmarshal(data, &set)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	keys := &jwks{rsa: map[string]*rsa.PublicKey{}, oct: map[string][]byte{}}
+	for i, key := range set.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+
+		switch key.Kty {
+		case "RSA":
+			publicKey, err := rsaPublicKey(key)
+			if err != nil {
+				return nil, fmt.Errorf("%s: key %d: %w", path, i, err)
+			}
+			keys.rsa[key.Kid] = publicKey
+		case "oct":
+			secret, err := base64.RawURLEncoding.DecodeString(key.K)
+			if err != nil || len(secret) == 0 {
+				return nil, fmt.Errorf("%s: key %d: invalid k", path, i)
+			}
+			keys.oct[key.Kid] = secret
+		}
+	}
+
+	if len(keys.rsa) == 0 && len(keys.oct) == 0 {
+		return nil, fmt.Errorf("%s: no RSA or oct signing keys", path)
+	}
+
+	return keys, nil
+}
+
+func rsaPublicKey(key jwk) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(key.N)
+	if err != nil || len(n) == 0 {
+		return nil, fmt.Errorf("invalid n")
+	}
+
+	e, err := base64.RawURLEncoding.DecodeString(key.E)
+	if err != nil || len(e) == 0 || len(e) > 4 {
+		return nil, fmt.Errorf("invalid e")
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}, nil
+}
+
+// find returns the key with kid from keys, or the only key when the token names none.
+func find[K any](keys map[string]K, kid string) (K, bool) {
+	if key, ok := keys[kid]; ok {
+		return key, true
+	}
+
+	var zero K
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, true
+		}
+	}
+
+	return zero, false
+}
@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/cyber/test-project/config"
+)
+
+type jwtVerifier struct {
+	keys   *jwks
+	parser *jwt.Parser
+}
+
+func newJWTVerifier(cfg config.JWTConfig) (*jwtVerifier, error) {
+	keys, err := loadJWKS(cfg.JWKSFile)
+	if err != nil {
+		return nil, fmt.Errorf("jwt.jwks_file: %w", err)
+	}
+
+	options := []jwt.ParserOption{
+		// The algorithm is pinned, so that an RSA public key is never accepted as an HMAC secret.
+		jwt.WithValidMethods(cfg.Algorithms),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(cfg.Leeway),
+	}
+	if cfg.Issuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		options = append(options, jwt.WithAudience(cfg.Audience))
+	}
+
+	return &jwtVerifier{keys: keys, parser: jwt.NewParser(options...)}, nil
+}
+
+// claims are the registered claims plus the scopes, granted either as a space separated `scope`
+// string (RFC 8693) or as a `scp` array.
+type claims struct {
+	jwt.RegisteredClaims
+	Scope string   `json:"scope"`
+	Scp   []string `json:"scp"`
+}
+
+func (v *jwtVerifier) verify(tokenString string) (Principal, error) {
+	var tokenClaims claims
+	_, err := v.parser.ParseWithClaims(tokenString, &tokenClaims, v.key)
+	if err != nil {
+		return Principal{}, fmt.Errorf("invalid token: %w", err)
+	}
+
+	return Principal{
+		Subject: tokenClaims.Subject,
+		Method:  MethodJWT,
+		Scopes:  append(strings.Fields(tokenClaims.Scope), tokenClaims.Scp...),
+	}, nil
+}
+
+func (v *jwtVerifier) key(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	var key any
+	var ok bool
+	switch token.Method.Alg() {
+	case config.JWTAlgorithmHS256:
+		key, ok = find(v.keys.oct, kid)
+	case config.JWTAlgorithmRS256:
+		key, ok = find(v.keys.rsa, kid)
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("no %s key with kid %q", token.Method.Alg(), kid)
+	}
+
+	return key, nil
+}
@@ -0,0 +1,104 @@
+// Package cassette records outbound HTTP interactions to a file and replays them, so clients can run
+// deterministically in tests and fully offline in demos.
+package cassette
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Cassette struct {
+	Interactions []Interaction `json:"interactions" yaml:"interactions"`
+}
+
+type Interaction struct {
+	RecordedAt time.Time `json:"recorded_at" yaml:"recorded_at"`
+	Request    Request   `json:"request" yaml:"request"`
+	Response   Response  `json:"response" yaml:"response"`
+}
+
+type Request struct {
+	Method  string      `json:"method" yaml:"method"`
+	URL     string      `json:"url" yaml:"url"`
+	Headers http.Header `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Body    string      `json:"body,omitempty" yaml:"body,omitempty"`
+}
+
+type Response struct {
+	Status  int         `json:"status" yaml:"status"`
+	Headers http.Header `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Body    string      `json:"body,omitempty" yaml:"body,omitempty"`
+}
+
+// Load reads a cassette, choosing JSON or YAML by the file extension. A missing file is an empty
+// cassette.
+func Load(path string) (*Cassette, error) {
+	encoded, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return &Cassette{}, nil
+		}
+		return nil, err
+	}
+
+	c := &Cassette{}
+	if isJSON(path) {
+		err = json.Unmarshal(encoded, c)
+	} else {
+		err = yaml.Unmarshal(encoded, c)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Save writes the cassette to a temporary file next to path and renames it, so a crash never leaves
+// a truncated cassette behind.
+func (c *Cassette) Save(path string) error {
+	var encoded []byte
+	var err error
+	if isJSON(path) {
+		encoded, err = json.MarshalIndent(c, "", "  ")
+	} else {
+		encoded, err = yaml.Marshal(c)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+
+	fh, err := os.CreateTemp(filepath.Dir(path), ".cassette-*")
+	if err != nil {
+		return err
+	}
+
+	_, err = fh.Write(encoded)
+	if err != nil {
+		return errors.Join(err, fh.Close(), os.Remove(fh.Name()))
+	}
+
+	err = fh.Close()
+	if err != nil {
+		return errors.Join(err, os.Remove(fh.Name()))
+	}
+
+	return os.Rename(fh.Name(), path)
+}
+
+func isJSON(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".json")
+}
@@ -0,0 +1,253 @@
+package cassette
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cyber/test-project/config"
+	"github.com/cyber/test-project/logging"
+)
+
+type ErrNoInteraction struct {
+	Method string
+	URL    string
+}
+
+func (e ErrNoInteraction) Error() string {
+	return "no recorded interaction matches " + e.Method + " " + e.URL
+}
+
+// Transport is an http.RoundTripper that, depending on the mode, records every interaction with the
+// wrapped transport or answers requests from the cassette without any network access. Sensitive
+// headers, query params, JSON keys and patterns configured for log redaction are scrubbed before
+// saving. Recorded interactions are written to the file by Flush and Close.
+type Transport struct {
+	mode     string
+	path     string
+	match    []string
+	next     http.RoundTripper
+	redactor *logging.Redactor
+
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+	// unsaved is set when interactions were recorded since the cassette was last saved.
+	unsaved bool
+}
+
+// NewTransport loads the cassette at cfg.Path. next is only used when recording and defaults to
+// http.DefaultTransport.
+func NewTransport(cfg config.CassetteConfig, redactor *logging.Redactor, next http.RoundTripper) (*Transport, error) {
+	c, err := Load(cfg.Path)
+	if err != nil {
+		return nil, fmt.Errorf("load cassette %s: %w", cfg.Path, err)
+	}
+
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &Transport{
+		mode:     cfg.Mode,
+		path:     cfg.Path,
+		match:    cfg.Match,
+		next:     next,
+		redactor: redactor,
+		cassette: c,
+		used:     make([]bool, len(c.Interactions)),
+	}, nil
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if body != nil {
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	if t.mode == config.CassetteModeReplay {
+		return t.replay(req, body)
+	}
+
+	return t.record(req, body)
+}
+
+func (t *Transport) record(req *http.Request, body []byte) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	interaction := Interaction{
+		RecordedAt: time.Now().UTC(),
+		Request:    t.scrubRequest(req, body),
+		Response: Response{
+			Status:  resp.StatusCode,
+			Headers: t.redactor.RedactHeader(resp.Header),
+			Body:    t.scrubBody(respBody),
+		},
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.cassette.Interactions = append(t.cassette.Interactions, interaction)
+	t.used = append(t.used, true)
+	t.unsaved = true
+
+	return resp, nil
+}
+
+// Flush saves the interactions recorded so far.
+func (t *Transport) Flush() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.unsaved {
+		return nil
+	}
+
+	err := t.cassette.Save(t.path)
+	if err != nil {
+		return fmt.Errorf("save cassette %s: %w", t.path, err)
+	}
+	t.unsaved = false
+
+	return nil
+}
+
+// Close saves the recorded interactions. The transport must not be used for recording afterwards.
+func (t *Transport) Close() error {
+	return t.Flush()
+}
+
+// replay answers with the first unused matching interaction, so repeated identical requests get
+// recorded responses in order. Once all of them were used, the last match is repeated.
+func (t *Transport) replay(req *http.Request, body []byte) (*http.Response, error) {
+	incoming := t.scrubRequest(req, body)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	found := -1
+	for i, interaction := range t.cassette.Interactions {
+		if !t.matches(interaction.Request, incoming) {
+			continue
+		}
+
+		found = i
+		if !t.used[i] {
+			break
+		}
+	}
+
+	if found < 0 {
+		return nil, ErrNoInteraction{Method: incoming.Method, URL: incoming.URL}
+	}
+	t.used[found] = true
+
+	recorded := t.cassette.Interactions[found].Response
+
+	return &http.Response{
+		Status:        strconv.Itoa(recorded.Status) + " " + http.StatusText(recorded.Status),
+		StatusCode:    recorded.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recorded.Headers.Clone(),
+		Body:          io.NopCloser(strings.NewReader(recorded.Body)),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}, nil
+}
+
+func (t *Transport) matches(recorded, incoming Request) bool {
+	recordedURL, err := url.Parse(recorded.URL)
+	if err != nil {
+		return false
+	}
+
+	incomingURL, err := url.Parse(incoming.URL)
+	if err != nil {
+		return false
+	}
+
+	for _, match := range t.match {
+		switch match {
+		case config.CassetteMatchMethod:
+			if recorded.Method != incoming.Method {
+				return false
+			}
+		case config.CassetteMatchPath:
+			if recordedURL.Path != incomingURL.Path {
+				return false
+			}
+		case config.CassetteMatchQuery:
+			if !reflect.DeepEqual(normalizeQuery(recordedURL.Query()), normalizeQuery(incomingURL.Query())) {
+				return false
+			}
+		case config.CassetteMatchBody:
+			if recorded.Body != incoming.Body {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func (t *Transport) scrubRequest(req *http.Request, body []byte) Request {
+	return Request{
+		Method:  req.Method,
+		URL:     t.redactor.RedactURL(req.URL.String()),
+		Headers: t.redactor.RedactHeader(req.Header),
+		Body:    t.scrubBody(body),
+	}
+}
+
+// scrubBody masks sensitive JSON keys of JSON bodies and redaction patterns in any body.
+func (t *Transport) scrubBody(body []byte) string {
+	return t.redactor.RedactString(string(t.redactor.RedactJSON(body)))
+}
+
+func normalizeQuery(query url.Values) url.Values {
+	for _, values := range query {
+		slices.Sort(values)
+	}
+
+	return query
+}
+
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
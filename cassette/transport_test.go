@@ -0,0 +1,133 @@
+package cassette
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cyber/test-project/config"
+	"github.com/cyber/test-project/logging"
+)
+
+func newTestTransport(t *testing.T, mode, path string, next http.RoundTripper) *Transport {
+	t.Helper()
+
+	redactor, err := logging.NewRedactor(config.RedactionConfig{
+		Headers:  []string{"Authorization"},
+		JSONKeys: []string{"email", "token"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transport, err := NewTransport(config.CassetteConfig{
+		Mode:  mode,
+		Path:  path,
+		Match: []string{config.CassetteMatchMethod, config.CassetteMatchPath, config.CassetteMatchBody},
+	}, redactor, next)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return transport
+}
+
+func TestTransportRecordsAndReplays(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"data": {"gid": "1201", "email": "ada@example.com"}}`)
+	}))
+	defer upstream.Close()
+
+	path := filepath.Join(t.TempDir(), "asana.yaml")
+	recorder := newTestTransport(t, config.CassetteModeRecord, path, nil)
+
+	req, err := http.NewRequest(http.MethodPost, upstream.URL+"/api/1.0/users", strings.NewReader(`{"token": "secret", "name": "Ada"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer secret")
+
+	resp, err := (&http.Client{Transport: recorder}).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if !strings.Contains(string(body), "ada@example.com") {
+		t.Errorf("recorded response body = %s, want it unredacted", body)
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("cassette saved before Flush: %v", err)
+	}
+
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	saved, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"secret", "ada@example.com"} {
+		if strings.Contains(string(saved), secret) {
+			t.Errorf("cassette contains %q:\n%s", secret, saved)
+		}
+	}
+
+	replayer := newTestTransport(t, config.CassetteModeReplay, path, nil)
+	replayed, err := replayer.RoundTrip(httptest.NewRequest(http.MethodPost, "http://asana.invalid/api/1.0/users", strings.NewReader(`{"name": "Ada", "token": "other"}`)))
+	if err != nil {
+		t.Fatalf("replay error = %v", err)
+	}
+	body, _ = io.ReadAll(replayed.Body)
+	if want := `{"data":{"email":"[REDACTED]","gid":"1201"}}`; string(body) != want {
+		t.Errorf("replayed body = %s, want %s", body, want)
+	}
+}
+
+func TestTransportFlushSavesOnlyNewInteractions(t *testing.T) {
+	calls := 0
+	next := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader("ok")), Request: req}, nil
+	})
+
+	path := filepath.Join(t.TempDir(), "asana.json")
+	transport := newTestTransport(t, config.CassetteModeRecord, path, next)
+
+	if err := transport.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("Flush() without interactions saved the cassette: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := transport.RoundTrip(httptest.NewRequest(http.MethodGet, "http://asana.invalid/api/1.0/users/me", nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := transport.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	saved, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved.Interactions) != calls {
+		t.Errorf("saved %d interactions, want %d", len(saved.Interactions), calls)
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
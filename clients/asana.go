@@ -2,29 +2,27 @@ package clients
 
 import (
 	"context"
-	"encoding/json"
-	"net/http"
 	"net/url"
 	"strconv"
 
-	"go.uber.org/zap"
-
-	"github.com/cyber/test-project/logging"
 	"github.com/cyber/test-project/models"
 )
 
 type AsanaClient struct {
-	baseClient *httpClient
+	baseClient *HttpClient
 }
 
 const (
 	getUsersEndpoint    = "/api/1.0/users"
 	getProjectsEndpoint = "/api/1.0/projects"
+	getMeEndpoint       = "/api/1.0/users/me"
+	getUserEndpoint     = "/api/1.0/users/{gid}"
+	getProjectEndpoint  = "/api/1.0/projects/{gid}"
 )
 
 func NewAsanaClient(options ClientOptions) *AsanaClient {
 	return &AsanaClient{
-		baseClient: newHttpClient(options),
+		baseClient: NewHttpClient(options),
 	}
 }
 
@@ -36,50 +34,69 @@ type GetUsersRequest struct {
 	Token     string
 }
 
-func (a AsanaClient) GetUsers(ctx context.Context, request GetUsersRequest) (models.AsanaGetUsersResponse, error) {
-	logger := logging.FromContext(ctx)
-	logger = logger.With(zap.String("operation_name", "asana_get_users"))
-	ctx = logging.WithLogger(ctx, logger)
+func (r GetUsersRequest) Query() url.Values {
+	return PageQuery(r.Limit, r.Offset, map[string]string{
+		"workspace": r.Workspace,
+		"team":      r.Team,
+	})
+}
 
-	query := url.Values{}
-	if request.Workspace != "" {
-		query.Set("workspace", request.Workspace)
-	}
+func (r GetUsersRequest) Path() string {
+	return getUsersEndpoint
+}
 
-	if request.Team != "" {
-		query.Set("team", request.Team)
-	}
-	if request.Limit != 0 {
-		query.Set("limit", strconv.Itoa(request.Limit))
-	}
-	if request.Offset != "" {
-		query.Set("offset", request.Offset)
+func (a AsanaClient) GetUsers(ctx context.Context, request GetUsersRequest) (models.AsanaGetUsersResponse, error) {
+	page, err := GetPage[models.AsanaUser](ctx, a.baseClient, Call{
+		Operation: "asana_get_users",
+		Path:      getUsersEndpoint,
+		Query:     request.Query(),
+		Token:     request.Token,
+	})
+	if err != nil {
+		return models.AsanaGetUsersResponse{}, err
 	}
 
-	req := httpRequest{
-		method: http.MethodGet,
-		path:   getUsersEndpoint,
-		query:  query,
-		headers: map[string]string{
-			"Accept":        "application/json",
-			"Authorization": "Bearer " + request.Token,
-		},
-	}
+	return models.AsanaGetUsersResponse{Data: page.Data, NextPage: page.NextPage}, nil
+}
 
-	resp, err := a.baseClient.doRequest(ctx, req)
-	// @TODO: process 429
+type GetUserRequest struct {
+	Gid   string
+	Token string
+}
+
+func (r GetUserRequest) Path() string {
+	return expandPath(getUserEndpoint, map[string]string{"gid": r.Gid})
+}
+
+func (a AsanaClient) GetUser(ctx context.Context, request GetUserRequest) (models.AsanaGetUserResponse, error) {
+	user, err := Get[models.AsanaUser](ctx, a.baseClient, Call{
+		Operation:  "asana_get_user",
+		Path:       getUserEndpoint,
+		PathParams: map[string]string{"gid": request.Gid},
+		Token:      request.Token,
+	})
 	if err != nil {
-		return models.AsanaGetUsersResponse{}, models.ErrServiceFailure{ServiceName: "asana"}
+		return models.AsanaGetUserResponse{}, err
 	}
 
-	var response models.AsanaGetUsersResponse
-	err = json.Unmarshal(resp, &response)
+	return models.AsanaGetUserResponse{Data: user}, nil
+}
+
+type GetMeRequest struct {
+	Token string
+}
+
+func (a AsanaClient) GetMe(ctx context.Context, request GetMeRequest) (models.AsanaGetUserResponse, error) {
+	user, err := Get[models.AsanaUser](ctx, a.baseClient, Call{
+		Operation: "asana_get_me",
+		Path:      getMeEndpoint,
+		Token:     request.Token,
+	})
 	if err != nil {
-		logger.Error("Failed to unmarshal get_users response", zap.Error(err))
-		return models.AsanaGetUsersResponse{}, models.ErrServiceFailure{ServiceName: "asana"}
+		return models.AsanaGetUserResponse{}, err
 	}
 
-	return response, nil
+	return models.AsanaGetUserResponse{Data: user}, nil
 }
 
 type GetProjectsRequest struct {
@@ -91,51 +108,55 @@ type GetProjectsRequest struct {
 	Archived  *bool
 }
 
-func (a AsanaClient) GetProjects(ctx context.Context, request GetProjectsRequest) (models.AsanaGetProjectsResponse, error) {
-	logger := logging.FromContext(ctx)
-	logger = logger.With(zap.String("operation_name", "asana_get_projects"))
-	ctx = logging.WithLogger(ctx, logger)
-
-	query := url.Values{}
-	if request.Workspace != "" {
-		query.Set("workspace", request.Workspace)
-	}
-
-	if request.Team != "" {
-		query.Set("team", request.Team)
+func (r GetProjectsRequest) Query() url.Values {
+	params := map[string]string{
+		"workspace": r.Workspace,
+		"team":      r.Team,
 	}
-	if request.Limit != 0 {
-		query.Set("limit", strconv.Itoa(request.Limit))
-	}
-	if request.Offset != "" {
-		query.Set("offset", request.Offset)
-	}
-	if request.Archived != nil {
-		query.Set("archived", strconv.FormatBool(*request.Archived))
+	if r.Archived != nil {
+		params["archived"] = strconv.FormatBool(*r.Archived)
 	}
 
-	req := httpRequest{
-		method: http.MethodGet,
-		path:   getProjectsEndpoint,
-		query:  query,
-		headers: map[string]string{
-			"Accept":        "application/json",
-			"Authorization": "Bearer " + request.Token,
-		},
-	}
+	return PageQuery(r.Limit, r.Offset, params)
+}
 
-	resp, err := a.baseClient.doRequest(ctx, req)
-	// @TODO: process 429
+func (r GetProjectsRequest) Path() string {
+	return getProjectsEndpoint
+}
+
+func (a AsanaClient) GetProjects(ctx context.Context, request GetProjectsRequest) (models.AsanaGetProjectsResponse, error) {
+	page, err := GetPage[models.AsanaProjectResource](ctx, a.baseClient, Call{
+		Operation: "asana_get_projects",
+		Path:      getProjectsEndpoint,
+		Query:     request.Query(),
+		Token:     request.Token,
+	})
 	if err != nil {
-		return models.AsanaGetProjectsResponse{}, models.ErrServiceFailure{ServiceName: "asana"}
+		return models.AsanaGetProjectsResponse{}, err
 	}
 
-	var response models.AsanaGetProjectsResponse
-	err = json.Unmarshal(resp, &response)
+	return models.AsanaGetProjectsResponse{Data: page.Data, NextPage: page.NextPage}, nil
+}
+
+type GetProjectRequest struct {
+	Gid   string
+	Token string
+}
+
+func (r GetProjectRequest) Path() string {
+	return expandPath(getProjectEndpoint, map[string]string{"gid": r.Gid})
+}
+
+func (a AsanaClient) GetProject(ctx context.Context, request GetProjectRequest) (models.AsanaGetProjectResponse, error) {
+	project, err := Get[models.AsanaProjectResource](ctx, a.baseClient, Call{
+		Operation:  "asana_get_project",
+		Path:       getProjectEndpoint,
+		PathParams: map[string]string{"gid": request.Gid},
+		Token:      request.Token,
+	})
 	if err != nil {
-		logger.Error("Failed to unmarshal get_users response", zap.Error(err))
-		return models.AsanaGetProjectsResponse{}, models.ErrServiceFailure{ServiceName: "asana"}
+		return models.AsanaGetProjectResponse{}, err
 	}
 
-	return response, nil
+	return models.AsanaGetProjectResponse{Data: project}, nil
 }
@@ -0,0 +1,181 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/cyber/test-project/asanatest"
+	"github.com/cyber/test-project/config"
+	"github.com/cyber/test-project/models"
+)
+
+func newTestAsanaClient(server *asanatest.Server, middlewares ...Middleware) *AsanaClient {
+	return NewAsanaClient(ClientOptions{
+		ServiceName: "asana",
+		BaseURL:     server.URL,
+		Middlewares: middlewares,
+	})
+}
+
+func TestAsanaClientGetUsersFollowsNextPage(t *testing.T) {
+	server := asanatest.NewServer(asanatest.DefaultFixtures())
+	defer server.Close()
+
+	client := newTestAsanaClient(server)
+
+	var (
+		gids  []string
+		pages int
+	)
+	request := GetUsersRequest{Workspace: "1000", Limit: 2, Token: asanatest.DefaultToken}
+	for {
+		response, err := client.GetUsers(context.Background(), request)
+		if err != nil {
+			t.Fatalf("GetUsers() error = %v", err)
+		}
+
+		pages++
+		for _, user := range response.Data {
+			gids = append(gids, user.Gid)
+		}
+
+		if response.NextPage.Offset == "" {
+			break
+		}
+		request.Offset = response.NextPage.Offset
+	}
+
+	if want := []string{"1201", "1202", "1203", "1204", "1205"}; !slices.Equal(gids, want) {
+		t.Errorf("gids = %v, want %v", gids, want)
+	}
+	if pages != 3 {
+		t.Errorf("pages = %d, want 3", pages)
+	}
+}
+
+func TestAsanaClientErrors(t *testing.T) {
+	retry := Retry("asana", config.RetryConfig{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+	})
+
+	tests := []struct {
+		name         string
+		gid          string
+		token        string
+		fault        *asanatest.Fault
+		middlewares  []Middleware
+		wantErr      error
+		wantRequests int
+		wantWait     time.Duration
+	}{
+		{
+			name:         "found",
+			gid:          "1201",
+			wantRequests: 1,
+		},
+		{
+			name:         "not found",
+			gid:          "404",
+			wantErr:      models.ErrServiceResponse{ServiceName: "asana", StatusCode: http.StatusNotFound, Messages: []string{"404: Not Found"}},
+			wantRequests: 1,
+		},
+		{
+			name:         "unauthorized",
+			gid:          "1201",
+			token:        "wrong",
+			wantErr:      models.ErrServiceResponse{ServiceName: "asana", StatusCode: http.StatusUnauthorized, Messages: []string{"Not Authorized"}},
+			wantRequests: 1,
+		},
+		{
+			name:         "rate limited",
+			gid:          "1201",
+			fault:        &asanatest.Fault{Status: http.StatusTooManyRequests, RetryAfter: time.Second},
+			wantErr:      models.ErrRateLimitExceeded{ServiceName: "asana"},
+			wantRequests: 1,
+		},
+		{
+			name:         "rate limited with retries follows Retry-After",
+			gid:          "1201",
+			fault:        &asanatest.Fault{Status: http.StatusTooManyRequests, RetryAfter: time.Second},
+			middlewares:  []Middleware{retry},
+			wantRequests: 2,
+			wantWait:     time.Second,
+		},
+		{
+			name:         "server error",
+			gid:          "1201",
+			fault:        &asanatest.Fault{Status: http.StatusBadGateway},
+			wantErr:      models.ErrServiceFailure{ServiceName: "asana"},
+			wantRequests: 1,
+		},
+		{
+			name:         "server error with retries",
+			gid:          "1201",
+			fault:        &asanatest.Fault{Status: http.StatusServiceUnavailable, Times: 2},
+			middlewares:  []Middleware{retry},
+			wantRequests: 3,
+		},
+		{
+			name:         "server error exhausting retries",
+			gid:          "1201",
+			fault:        &asanatest.Fault{Status: http.StatusInternalServerError, Times: 3},
+			middlewares:  []Middleware{retry},
+			wantErr:      models.ErrServiceFailure{ServiceName: "asana"},
+			wantRequests: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := asanatest.NewServer(asanatest.DefaultFixtures())
+			defer server.Close()
+
+			path := asanatest.UsersPath + "/" + tt.gid
+			if tt.fault != nil {
+				server.InjectFault(path, *tt.fault)
+			}
+
+			token := tt.token
+			if token == "" {
+				token = asanatest.DefaultToken
+			}
+
+			started := time.Now()
+			response, err := newTestAsanaClient(server, tt.middlewares...).GetUser(context.Background(), GetUserRequest{Gid: tt.gid, Token: token})
+
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("GetUser() error = %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) && !equalServiceResponse(err, tt.wantErr) {
+				t.Fatalf("GetUser() error = %#v, want %#v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && response.Data.Gid != tt.gid {
+				t.Errorf("gid = %q, want %q", response.Data.Gid, tt.gid)
+			}
+
+			if got := server.Requests(path); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+			if elapsed := time.Since(started); elapsed < tt.wantWait {
+				t.Errorf("elapsed = %s, want at least %s", elapsed, tt.wantWait)
+			}
+		})
+	}
+}
+
+// equalServiceResponse compares ErrServiceResponse values, which errors.Is cannot because of their
+// Messages slice.
+func equalServiceResponse(err, target error) bool {
+	var got, want models.ErrServiceResponse
+	if !errors.As(err, &got) || !errors.As(target, &want) {
+		return false
+	}
+
+	return got.ServiceName == want.ServiceName && got.StatusCode == want.StatusCode && slices.Equal(got.Messages, want.Messages)
+}
@@ -1,9 +1,14 @@
 package clients
 
 import (
+	"context"
+	"errors"
+	"sync"
+
 	"github.com/sony/gobreaker"
 
 	"github.com/cyber/test-project/config"
+	"github.com/cyber/test-project/models"
 )
 
 type CircuitBreaker interface {
@@ -16,11 +21,78 @@ func (b noCircuitBreaker) Execute(req func() (any, error)) (any, error) {
 	return req()
 }
 
-func NewCircuitBreaker(cfg config.CircuitBreakerConfig) CircuitBreaker {
+// ReloadableCircuitBreaker allows changing breaker thresholds at runtime. gobreaker settings are
+// immutable, so an update replaces the underlying breaker and resets its state.
+type ReloadableCircuitBreaker struct {
+	mu      sync.RWMutex
+	cfg     config.CircuitBreakerConfig
+	breaker *gobreaker.CircuitBreaker
+}
+
+func NewCircuitBreaker(cfg config.CircuitBreakerConfig) *ReloadableCircuitBreaker {
+	return &ReloadableCircuitBreaker{
+		cfg:     cfg,
+		breaker: newGoBreaker(cfg),
+	}
+}
+
+func (b *ReloadableCircuitBreaker) Execute(req func() (any, error)) (any, error) {
+	b.mu.RLock()
+	breaker := b.breaker
+	b.mu.RUnlock()
+
+	return breaker.Execute(req)
+}
+
+func (b *ReloadableCircuitBreaker) State() gobreaker.State {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.breaker.State()
+}
+
+// StateValue reports the breaker state as a number suitable for a gauge: 0 - closed, 1 - half-open, 2 - open.
+func (b *ReloadableCircuitBreaker) StateValue() float64 {
+	switch b.State() {
+	case gobreaker.StateHalfOpen:
+		return 1
+	case gobreaker.StateOpen:
+		return 2
+	default:
+		return 0
+	}
+}
+
+func (b *ReloadableCircuitBreaker) Check(context.Context) error {
+	if b.State() == gobreaker.StateOpen {
+		return errors.New("circuit breaker is open")
+	}
+
+	return nil
+}
+
+func (b *ReloadableCircuitBreaker) Update(cfg config.CircuitBreakerConfig) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.cfg == cfg {
+		return
+	}
+
+	b.cfg = cfg
+	b.breaker = newGoBreaker(cfg)
+}
+
+func newGoBreaker(cfg config.CircuitBreakerConfig) *gobreaker.CircuitBreaker {
 	cbSett := gobreaker.Settings{
 		Name:        cfg.Name,
 		MaxRequests: cfg.MaxRequests,
 		Timeout:     cfg.Timeout,
+		// A caller running out of its own budget says nothing about the service's health.
+		IsSuccessful: func(err error) bool {
+			var deadlineErr models.ErrDeadlineExceeded
+			return err == nil || errors.As(err, &deadlineErr)
+		},
 	}
 
 	if cfg.MaxFailures > 0 {
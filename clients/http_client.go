@@ -9,14 +9,22 @@ import (
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"slices"
+	"time"
 
 	"github.com/sony/gobreaker"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
 	"github.com/cyber/test-project/appcontext"
 	"github.com/cyber/test-project/logging"
+	"github.com/cyber/test-project/metrics"
 	"github.com/cyber/test-project/models"
+	"github.com/cyber/test-project/tracing"
 )
 
 var successCodes = map[int]bool{
@@ -27,15 +35,18 @@ var successCodes = map[int]bool{
 }
 
 type httpRequest struct {
-	method  string
-	path    string
+	method string
+	path   string
+	// route is the template path was expanded from, e.g. /api/1.0/users/{gid}. It names the request
+	// in spans and metrics; path is used when it is empty.
+	route   string
 	body    any
 	query   url.Values
 	headers map[string]string
 }
 
 func (r httpRequest) toHttpRequest(ctx context.Context, baseUrl string) (*http.Request, error) {
-	logger := logging.FromContext(ctx)
+	logger := appcontext.Logger(ctx)
 
 	var bodyReader io.Reader
 	if r.body != nil {
@@ -68,40 +79,106 @@ func (r httpRequest) toHttpRequest(ctx context.Context, baseUrl string) (*http.R
 				q.Add(key, param)
 			}
 		}
+		req.URL.RawQuery = q.Encode()
 	}
 
 	return req.WithContext(ctx), nil
 }
 
 type ClientOptions struct {
-	ServiceName string
-	BaseClient  *http.Client
-	BaseURL     string
+	ServiceName    string
+	BaseClient     *http.Client
+	BaseURL        string
+	CircuitBreaker CircuitBreaker
+	RateLimiter    RateLimiter
+	// Middlewares wrap BaseClient's transport, the first one outermost. They run for every attempt
+	// inside the circuit breaker, around the built-in Tracing and Metrics middlewares.
+	Middlewares []Middleware
 }
 
-type httpClient struct {
+// HttpClient sends requests to one upstream service with rate limiting, circuit breaking and the
+// configured middlewares. JSON endpoints are built on it with Do, Get and GetPage.
+type HttpClient struct {
 	serviceName    string
 	baseClient     *http.Client
 	baseUrl        string
 	circuitBreaker CircuitBreaker
+	rateLimiter    RateLimiter
 }
 
-func newHttpClient(options ClientOptions) *httpClient {
-	return &httpClient{
+func NewHttpClient(options ClientOptions) *HttpClient {
+	baseClient := &http.Client{}
+	if options.BaseClient != nil {
+		*baseClient = *options.BaseClient
+	}
+
+	middlewares := append(slices.Clone(options.Middlewares), Tracing(), Metrics(options.ServiceName))
+	baseClient.Transport = Chain(baseClient.Transport, middlewares...)
+
+	client := &HttpClient{
 		serviceName:    options.ServiceName,
-		baseClient:     options.BaseClient,
+		baseClient:     baseClient,
 		baseUrl:        options.BaseURL,
-		circuitBreaker: noCircuitBreaker{},
+		circuitBreaker: options.CircuitBreaker,
+		rateLimiter:    options.RateLimiter,
+	}
+
+	if client.circuitBreaker == nil {
+		client.circuitBreaker = noCircuitBreaker{}
 	}
+
+	if client.rateLimiter == nil {
+		client.rateLimiter = noRateLimiter{}
+	}
+
+	return client
 }
 
-func (c httpClient) doRequest(ctx context.Context, req httpRequest) ([]byte, error) {
-	logger := logging.FromContext(ctx).
+func (c HttpClient) doRequest(ctx context.Context, req httpRequest) ([]byte, error) {
+	logger := logging.ForComponent(appcontext.Logger(ctx), "clients").
 		With(zap.String("method", req.method)).
 		With(zap.String("path", req.path)).
 		With(zap.String("service", c.serviceName))
 	ctx = appcontext.WithLogger(ctx, logger)
 
+	route := req.route
+	if route == "" {
+		route = req.path
+	}
+	ctx = withRoute(ctx, route)
+
+	ctx, span := tracing.Start(ctx, c.serviceName+" "+req.method+" "+route,
+		trace.WithAttributes(
+			attribute.String("service", c.serviceName),
+			semconv.HTTPRequestMethodKey.String(req.method),
+			semconv.URLPath(req.path),
+			semconv.HTTPRoute(route),
+		),
+	)
+	defer span.End()
+
+	if remaining, ok := appcontext.RemainingBudget(ctx); ok {
+		logger = logger.With(zap.Duration("budget_remaining", remaining))
+		ctx = appcontext.WithLogger(ctx, logger)
+		span.SetAttributes(attribute.Int64("request.budget_remaining_ms", remaining.Milliseconds()))
+
+		if remaining <= 0 {
+			logger.Warn("Request budget exhausted before calling " + c.serviceName)
+			return nil, models.ErrDeadlineExceeded{ServiceName: c.serviceName}
+		}
+	}
+
+	waitStarted := time.Now()
+	err := c.rateLimiter.Wait(ctx)
+	metrics.ObserveRateLimitWait(c.serviceName, time.Since(waitStarted))
+	if err != nil {
+		logger.Warn("Rate limiter wait aborted", zap.Error(err))
+		if ctx.Err() != nil {
+			return nil, models.ErrDeadlineExceeded{ServiceName: c.serviceName}
+		}
+		return nil, models.ErrRateLimitExceeded{ServiceName: c.serviceName}
+	}
+
 	httpReq, err := req.toHttpRequest(ctx, c.baseUrl)
 	if err != nil {
 		return nil, err
@@ -109,7 +186,8 @@ func (c httpClient) doRequest(ctx context.Context, req httpRequest) ([]byte, err
 
 	resp, err := c.do(httpReq)
 	if err != nil {
-		return nil, models.ErrServiceFailure{ServiceName: c.serviceName}
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
 	}
 	defer closeBody(resp, logger)
 
@@ -125,18 +203,39 @@ func (c httpClient) doRequest(ctx context.Context, req httpRequest) ([]byte, err
 		return respBodyBytes, nil
 	}
 
-	return nil, c.handleErrorResponse(ctx, logger, resp.StatusCode, respBodyBytes)
+	logger.Debug("got an unsuccessful response from "+c.serviceName,
+		logging.DebugField(logger, func() zapcore.Field {
+			respDump, _ := httputil.DumpResponse(resp, false)
+			return zap.ByteString("response_dump", append(respDump, respBodyBytes...))
+		}),
+	)
+
+	err = c.handleErrorResponse(ctx, logger, resp.StatusCode, respBodyBytes)
+	span.SetStatus(codes.Error, err.Error())
+
+	return nil, err
 }
 
-func (c httpClient) do(req *http.Request) (*http.Response, error) {
+func (c HttpClient) do(req *http.Request) (*http.Response, error) {
 	ctx := req.Context()
-	logger := logging.FromContext(ctx)
+	logger := appcontext.Logger(ctx)
 
 	resp, err := c.circuitBreaker.Execute(func() (any, error) {
 		resp, httpErr := c.baseClient.Do(req)
+		// A canceled caller, e.g. the losing attempt of a hedged request, is no service failure either.
+		if httpErr != nil && errors.Is(ctx.Err(), context.Canceled) {
+			logger.Debug("Request to "+c.serviceName+" canceled", zap.Error(httpErr))
+			return nil, models.ErrDeadlineExceeded{ServiceName: c.serviceName}
+		}
+
+		if httpErr != nil && ctx.Err() != nil {
+			logger.Warn("Request budget exhausted while calling "+c.serviceName, zap.Error(httpErr))
+			return nil, models.ErrDeadlineExceeded{ServiceName: c.serviceName}
+		}
+
 		if httpErr != nil {
 			logger.Error("could not perform HTTP request",
-				logging.DebugField(func() zapcore.Field {
+				logging.DebugField(logger, func() zapcore.Field {
 					reqDump, _ := httputil.DumpRequest(req, true)
 					return zap.ByteString("request_dump", reqDump)
 				}),
@@ -147,6 +246,7 @@ func (c httpClient) do(req *http.Request) (*http.Response, error) {
 
 		if resp.StatusCode >= http.StatusInternalServerError {
 			logger.Debug("got a 5xx HTTP status code from "+c.serviceName, zap.Int("status_code", resp.StatusCode))
+			closeBody(resp, logger)
 			return nil, models.ErrServiceFailure{ServiceName: c.serviceName}
 		}
 
@@ -160,16 +260,41 @@ func (c httpClient) do(req *http.Request) (*http.Response, error) {
 			return nil, models.ErrRateLimitExceeded{ServiceName: c.serviceName}
 		}
 
+		var deadlineErr models.ErrDeadlineExceeded
+		if errors.As(err, &deadlineErr) {
+			return nil, err
+		}
+
 		return nil, models.ErrServiceFailure{ServiceName: c.serviceName}
 	}
 
 	return response, nil
 }
 
-func (c httpClient) handleErrorResponse(ctx context.Context, logger *zap.Logger, statusCode int, respBodyBytes []byte) error {
-	switch statusCode {
-	case http.StatusTooManyRequests:
+// errorResponse is the error body of Asana-style APIs: `{"errors": [{"message": "..."}]}`.
+type errorResponse struct {
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func (c HttpClient) handleErrorResponse(ctx context.Context, logger *zap.Logger, statusCode int, respBodyBytes []byte) error {
+	switch {
+	case statusCode == http.StatusTooManyRequests:
 		return models.ErrRateLimitExceeded{ServiceName: c.serviceName}
+	case statusCode >= http.StatusBadRequest && statusCode < http.StatusInternalServerError:
+		var decoded errorResponse
+		err := json.Unmarshal(respBodyBytes, &decoded)
+		if err != nil {
+			logger.Debug("Failed to decode error response", zap.Error(err))
+		}
+
+		var messages []string
+		for _, e := range decoded.Errors {
+			messages = append(messages, e.Message)
+		}
+
+		return models.ErrServiceResponse{ServiceName: c.serviceName, StatusCode: statusCode, Messages: messages}
 	default:
 		return models.ErrServiceFailure{ServiceName: c.serviceName}
 	}
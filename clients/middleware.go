@@ -0,0 +1,197 @@
+package clients
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/cyber/test-project/appcontext"
+	"github.com/cyber/test-project/logging"
+	"github.com/cyber/test-project/metrics"
+	"github.com/cyber/test-project/tracing"
+)
+
+// Middleware wraps the transport of a client to add behavior to every outbound HTTP attempt.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Chain wraps base with middlewares; the first middleware is the outermost one.
+func Chain(base http.RoundTripper, middlewares ...Middleware) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		base = middlewares[i](base)
+	}
+
+	return base
+}
+
+// SetHeader sets header to the value returned by value unless the request already has it or the
+// value is empty.
+func SetHeader(header string, value func(*http.Request) string) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get(header) == "" {
+				if v := value(req); v != "" {
+					req = req.Clone(req.Context())
+					req.Header.Set(header, v)
+				}
+			}
+
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// BearerAuth injects an `Authorization: Bearer` header with the token returned by token.
+func BearerAuth(token func(*http.Request) string) Middleware {
+	return SetHeader("Authorization", func(req *http.Request) string {
+		if t := token(req); t != "" {
+			return "Bearer " + t
+		}
+		return ""
+	})
+}
+
+// ContextBearerAuth injects the token carried by the request context, see appcontext.WithToken.
+func ContextBearerAuth() Middleware {
+	return BearerAuth(func(req *http.Request) string {
+		return appcontext.Token(req.Context())
+	})
+}
+
+func UserAgent(userAgent string) Middleware {
+	return SetHeader("User-Agent", func(*http.Request) string {
+		return userAgent
+	})
+}
+
+// Logging writes a debug line per attempt with its URL, status and duration. The method and service
+// come from the request logger.
+func Logging() Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			logger := logging.ForComponent(appcontext.Logger(req.Context()), "clients")
+
+			started := time.Now()
+			resp, err := next.RoundTrip(req)
+
+			fields := []zap.Field{
+				zap.String("url", req.URL.Redacted()),
+				zap.Duration("duration", time.Since(started)),
+			}
+			if err != nil {
+				logger.Debug("outbound request failed", append(fields, zap.Error(err))...)
+				return nil, err
+			}
+
+			logger.Debug("outbound request", append(fields, zap.Int("status_code", resp.StatusCode))...)
+
+			return resp, nil
+		})
+	}
+}
+
+// Metrics records the count and latency of every attempt by service, method, path and status.
+func Metrics(serviceName string) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			started := time.Now()
+			resp, err := next.RoundTrip(req)
+
+			status := metrics.StatusError
+			if err == nil {
+				status = strconv.Itoa(resp.StatusCode)
+			}
+			path, ok := routeFrom(req.Context())
+			if !ok {
+				path = req.URL.Path
+			}
+			metrics.ObserveOutboundRequest(serviceName, req.Method, path, status, time.Since(started))
+
+			return resp, err
+		})
+	}
+}
+
+type routeKey struct{}
+
+func withRoute(ctx context.Context, route string) context.Context {
+	return context.WithValue(ctx, routeKey{}, route)
+}
+
+// routeFrom returns the path template of the request being sent, which keeps the path label of
+// metrics free of resource ids.
+func routeFrom(ctx context.Context) (string, bool) {
+	route, ok := ctx.Value(routeKey{}).(string)
+
+	return route, ok
+}
+
+// Tracing records a client span per attempt and propagates it in the W3C trace headers.
+func Tracing() Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			ctx, span := tracing.Start(req.Context(), "HTTP "+req.Method, trace.WithSpanKind(trace.SpanKindClient))
+			defer span.End()
+
+			req = req.Clone(ctx)
+			tracing.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+			resp, err := next.RoundTrip(req)
+			traceAttempt(span, req, resp, err)
+
+			return resp, err
+		})
+	}
+}
+
+func traceAttempt(span trace.Span, req *http.Request, resp *http.Response, err error) {
+	span.SetAttributes(
+		semconv.HTTPRequestMethodKey.String(req.Method),
+		semconv.ServerAddress(req.URL.Hostname()),
+		semconv.URLPath(req.URL.Path),
+	)
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+	if resp.StatusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+	}
+}
+
+// RateLimit waits for limiter before every attempt, including retries. ClientOptions.RateLimiter
+// waits once per request instead.
+func RateLimit(serviceName string, limiter RateLimiter) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			started := time.Now()
+			err := limiter.Wait(req.Context())
+			metrics.ObserveRateLimitWait(serviceName, time.Since(started))
+			if err != nil {
+				return nil, err
+			}
+
+			return next.RoundTrip(req)
+		})
+	}
+}
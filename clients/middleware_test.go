@@ -0,0 +1,49 @@
+package clients
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cyber/test-project/appcontext"
+)
+
+func TestContextBearerAuth(t *testing.T) {
+	tests := []struct {
+		name       string
+		ctxToken   string
+		header     string
+		wantHeader string
+	}{
+		{name: "context token", ctxToken: "tenant-token", wantHeader: "Bearer tenant-token"},
+		{name: "no context token", wantHeader: ""},
+		{name: "explicit header kept", ctxToken: "tenant-token", header: "Bearer call-token", wantHeader: "Bearer call-token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			transport := Chain(RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				got = req.Header.Get("Authorization")
+				return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+			}), ContextBearerAuth())
+
+			ctx := context.Background()
+			if tt.ctxToken != "" {
+				ctx = appcontext.WithToken(ctx, tt.ctxToken)
+			}
+			req := httptest.NewRequest(http.MethodGet, "http://asana.invalid/api/1.0/users/me", nil).WithContext(ctx)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+
+			if _, err := transport.RoundTrip(req); err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.wantHeader {
+				t.Errorf("Authorization = %q, want %q", got, tt.wantHeader)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package clients
+
+import (
+	"context"
+
+	"golang.org/x/time/rate"
+
+	"github.com/cyber/test-project/config"
+)
+
+type RateLimiter interface {
+	Wait(ctx context.Context) error
+}
+
+type noRateLimiter struct{}
+
+func (l noRateLimiter) Wait(context.Context) error {
+	return nil
+}
+
+type ReloadableRateLimiter struct {
+	limiter *rate.Limiter
+}
+
+func NewRateLimiter(cfg config.RateLimitConfig) *ReloadableRateLimiter {
+	return &ReloadableRateLimiter{
+		limiter: rate.NewLimiter(limitFromConfig(cfg), cfg.Burst),
+	}
+}
+
+func (l *ReloadableRateLimiter) Wait(ctx context.Context) error {
+	return l.limiter.Wait(ctx)
+}
+
+func (l *ReloadableRateLimiter) Update(cfg config.RateLimitConfig) {
+	l.limiter.SetLimit(limitFromConfig(cfg))
+	l.limiter.SetBurst(cfg.Burst)
+}
+
+func limitFromConfig(cfg config.RateLimitConfig) rate.Limit {
+	if cfg.RequestsPerSecond <= 0 {
+		return rate.Inf
+	}
+
+	return rate.Limit(cfg.RequestsPerSecond)
+}
@@ -0,0 +1,131 @@
+package clients
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/cyber/test-project/appcontext"
+	"github.com/cyber/test-project/config"
+	"github.com/cyber/test-project/logging"
+	"github.com/cyber/test-project/metrics"
+)
+
+var idempotentMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodPut:     true,
+	http.MethodDelete:  true,
+}
+
+// Retry repeats idempotent requests that failed with a transport error, 429 or 5xx, up to
+// cfg.MaxAttempts attempts in total. Backoff doubles from cfg.InitialBackoff with jitter, is capped at
+// cfg.MaxBackoff and follows a Retry-After header when the upstream sends one. A retry is skipped when
+// less than cfg.MinRemainingBudget of the request budget would be left after the backoff.
+func Retry(serviceName string, cfg config.RetryConfig) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if cfg.MaxAttempts <= 1 || !idempotentMethods[req.Method] || (req.Body != nil && req.GetBody == nil) {
+				return next.RoundTrip(req)
+			}
+
+			ctx := req.Context()
+			logger := logging.ForComponent(appcontext.Logger(ctx), "clients")
+
+			for attempt := 1; ; attempt++ {
+				attemptReq, err := rewind(req, attempt)
+				if err != nil {
+					return nil, err
+				}
+
+				resp, err := next.RoundTrip(attemptReq)
+				if attempt >= cfg.MaxAttempts || !retryable(resp, err) {
+					return resp, err
+				}
+
+				wait := backoff(cfg, attempt, resp)
+				if remaining, ok := appcontext.RemainingBudget(ctx); ok && remaining-wait < cfg.MinRemainingBudget {
+					logger.Debug("not retrying outbound request, request budget too low",
+						zap.Int("attempt", attempt),
+						zap.Duration("backoff", wait),
+						zap.Duration("budget_remaining", remaining),
+					)
+					return resp, err
+				}
+
+				if resp != nil {
+					closeBody(resp, logger)
+				}
+
+				logger.Debug("retrying outbound request",
+					zap.Int("attempt", attempt),
+					zap.Duration("backoff", wait),
+					zap.Error(err),
+				)
+				metrics.IncOutboundRetries(serviceName)
+
+				err = sleep(ctx, wait)
+				if err != nil {
+					return nil, err
+				}
+			}
+		})
+	}
+}
+
+func rewind(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 1 || req.GetBody == nil {
+		return req, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.Clone(req.Context())
+	req.Body = body
+
+	return req, nil
+}
+
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+}
+
+func backoff(cfg config.RetryConfig, attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return min(time.Duration(seconds)*time.Second, cfg.MaxBackoff)
+		}
+	}
+
+	wait := cfg.InitialBackoff << (attempt - 1)
+	if wait <= 0 || wait > cfg.MaxBackoff {
+		wait = cfg.MaxBackoff
+	}
+
+	// Jitter within the upper half keeps retries of concurrent requests apart.
+	return wait/2 + rand.N(wait/2+1)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
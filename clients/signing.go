@@ -0,0 +1,77 @@
+package clients
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	SignatureHeader          = "X-Signature"
+	SignatureKeyIDHeader     = "X-Signature-Key-Id"
+	SignatureTimestampHeader = "X-Signature-Timestamp"
+)
+
+// Signer adds a signature to an outbound request. body is the full request body, possibly empty.
+type Signer interface {
+	Sign(req *http.Request, body []byte) error
+}
+
+// SignRequests signs every attempt with signer, so retries carry a fresh signature.
+func SignRequests(signer Signer) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			var body []byte
+			if req.Body != nil && req.Body != http.NoBody {
+				var err error
+				body, err = io.ReadAll(req.Body)
+				_ = req.Body.Close()
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			req = req.Clone(req.Context())
+			if body != nil {
+				req.Body = io.NopCloser(bytes.NewReader(body))
+			}
+
+			err := signer.Sign(req, body)
+			if err != nil {
+				return nil, err
+			}
+
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// HMACSigner signs `<method>\n<request URI>\n<unix timestamp>\n<hex sha256 of body>` with HMAC-SHA256
+// and sends it as `X-Signature: v1=<hex>` along with the key id and timestamp headers.
+type HMACSigner struct {
+	keyID  string
+	secret []byte
+}
+
+func NewHMACSigner(keyID string, secret []byte) *HMACSigner {
+	return &HMACSigner{keyID: keyID, secret: secret}
+}
+
+func (s *HMACSigner) Sign(req *http.Request, body []byte) error {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	bodyHash := sha256.Sum256(body)
+
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(req.Method + "\n" + req.URL.RequestURI() + "\n" + timestamp + "\n" + hex.EncodeToString(bodyHash[:])))
+
+	req.Header.Set(SignatureKeyIDHeader, s.keyID)
+	req.Header.Set(SignatureTimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, "v1="+hex.EncodeToString(mac.Sum(nil)))
+
+	return nil
+}
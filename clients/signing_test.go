@@ -0,0 +1,106 @@
+package clients
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cyber/test-project/config"
+)
+
+// verifyHMAC recomputes the signature of HMACSigner from what the server received.
+func verifyHMAC(r *http.Request, body []byte, secret []byte) bool {
+	bodyHash := sha256.Sum256(body)
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(r.Method + "\n" + r.URL.RequestURI() + "\n" + r.Header.Get(SignatureTimestampHeader) + "\n" + hex.EncodeToString(bodyHash[:])))
+
+	return hmac.Equal([]byte(r.Header.Get(SignatureHeader)), []byte("v1="+hex.EncodeToString(mac.Sum(nil))))
+}
+
+func TestRetrySignsEveryAttempt(t *testing.T) {
+	secret := []byte("signing-secret")
+	const payload = `{"name": "Ada"}`
+
+	tests := []struct {
+		name         string
+		method       string
+		body         string
+		failures     int
+		wantStatus   int
+		wantAttempts int
+	}{
+		{name: "body retried twice", method: http.MethodPut, body: payload, failures: 2, wantStatus: http.StatusOK, wantAttempts: 3},
+		{name: "no body", method: http.MethodGet, failures: 1, wantStatus: http.StatusOK, wantAttempts: 2},
+		{name: "attempts exhausted", method: http.MethodPut, body: payload, failures: 5, wantStatus: http.StatusServiceUnavailable, wantAttempts: 3},
+		{name: "not idempotent", method: http.MethodPost, body: payload, failures: 1, wantStatus: http.StatusServiceUnavailable, wantAttempts: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mu       sync.Mutex
+				attempts int
+			)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				if err != nil {
+					t.Errorf("reading body: %v", err)
+				}
+
+				mu.Lock()
+				attempts++
+				attempt := attempts
+				mu.Unlock()
+
+				if string(body) != tt.body {
+					t.Errorf("attempt %d: body = %q, want %q", attempt, body, tt.body)
+				}
+				if r.Header.Get(SignatureKeyIDHeader) != "key-1" || !verifyHMAC(r, body, secret) {
+					t.Errorf("attempt %d: signature %q does not match the received body", attempt, r.Header.Get(SignatureHeader))
+				}
+
+				if attempt <= tt.failures {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			transport := Chain(http.DefaultTransport,
+				Retry("signing_test", config.RetryConfig{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}),
+				SignRequests(NewHMACSigner("key-1", secret)),
+			)
+
+			var body io.Reader
+			if tt.body != "" {
+				body = strings.NewReader(tt.body)
+			}
+			req, err := http.NewRequest(tt.method, server.URL+"/api/1.0/users?workspace=1000", body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := transport.RoundTrip(req)
+			if err != nil {
+				t.Fatalf("RoundTrip() error = %v", err)
+			}
+			_ = resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
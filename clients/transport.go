@@ -0,0 +1,98 @@
+package clients
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/cyber/test-project/config"
+)
+
+// NewBaseClient builds the http.Client of one upstream service from its transport settings.
+func NewBaseClient(cfg config.TransportConfig) (*http.Client, error) {
+	transport, err := NewTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   cfg.Timeout,
+	}, nil
+}
+
+// NewTransport clones http.DefaultTransport and applies cfg to it.
+func NewTransport(cfg config.TransportConfig) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	transport.DialContext = (&net.Dialer{
+		Timeout:   cfg.DialTimeout,
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+	transport.TLSHandshakeTimeout = cfg.TLSHandshakeTimeout
+	transport.ResponseHeaderTimeout = cfg.ResponseHeaderTimeout
+	transport.IdleConnTimeout = cfg.IdleConnTimeout
+	transport.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+
+	if cfg.ProxyURL != "" {
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("proxy_url: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	tlsConfig, err := newTLSConfig(cfg.TLS)
+	if err != nil {
+		return nil, err
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	// A custom TLS config turns off HTTP/2 unless it is forced, and an empty TLSNextProto turns it off
+	// for good.
+	transport.ForceAttemptHTTP2 = !cfg.DisableHTTP2
+	if cfg.DisableHTTP2 {
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+
+	return transport, nil
+}
+
+func newTLSConfig(cfg config.TransportTLSConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: cfg.ServerName,
+	}
+
+	if cfg.CAFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls.ca_file: %w", err)
+		}
+
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("tls.ca_file: no certificates found in %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls.cert_file: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
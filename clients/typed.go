@@ -0,0 +1,126 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/cyber/test-project/appcontext"
+	"github.com/cyber/test-project/logging"
+	"github.com/cyber/test-project/models"
+)
+
+// Call describes one JSON request to an upstream service.
+type Call struct {
+	// Operation names the call in logs, e.g. asana_get_users.
+	Operation string
+	Method    string
+	// Path may contain {name} placeholders, filled in from PathParams.
+	Path       string
+	PathParams map[string]string
+	Query      url.Values
+	Headers    map[string]string
+	// Token is sent as a bearer token when set.
+	Token string
+}
+
+// NoBody is the request type of calls that send no body.
+type NoBody struct{}
+
+// Envelope is the `data` wrapper responses are returned in, with pagination metadata for lists.
+type Envelope[T any] struct {
+	Data     T                    `json:"data"`
+	NextPage models.AsanaNextPage `json:"next_page,omitempty"`
+}
+
+// Do sends body as JSON, unless it is NoBody, and decodes the response into Resp. Error responses
+// are returned as the errors of HttpClient.
+func Do[Req, Resp any](ctx context.Context, c *HttpClient, call Call, body Req) (Resp, error) {
+	var response Resp
+
+	logger := logging.ForComponent(appcontext.Logger(ctx), "clients").With(zap.String("operation_name", call.Operation))
+	ctx = appcontext.WithLogger(ctx, logger)
+
+	headers := map[string]string{"Accept": "application/json"}
+	for key, value := range call.Headers {
+		headers[key] = value
+	}
+	if call.Token != "" {
+		headers["Authorization"] = "Bearer " + call.Token
+	}
+
+	req := httpRequest{
+		method:  call.Method,
+		path:    expandPath(call.Path, call.PathParams),
+		route:   call.Path,
+		query:   call.Query,
+		headers: headers,
+	}
+	if _, ok := any(body).(NoBody); !ok {
+		req.body = body
+		headers["Content-Type"] = "application/json"
+	}
+
+	resp, err := c.doRequest(ctx, req)
+	if err != nil {
+		return response, err
+	}
+
+	err = json.Unmarshal(resp, &response)
+	if err != nil {
+		logger.Error("Failed to unmarshal "+call.Operation+" response", zap.Error(err))
+		return response, models.ErrServiceFailure{ServiceName: c.serviceName}
+	}
+
+	return response, nil
+}
+
+// Get fetches a single resource wrapped in a `data` envelope.
+func Get[T any](ctx context.Context, c *HttpClient, call Call) (T, error) {
+	call.Method = http.MethodGet
+
+	envelope, err := Do[NoBody, Envelope[T]](ctx, c, call, NoBody{})
+
+	return envelope.Data, err
+}
+
+// GetPage fetches one page of a list together with the offset of the next page.
+func GetPage[T any](ctx context.Context, c *HttpClient, call Call) (Envelope[[]T], error) {
+	call.Method = http.MethodGet
+
+	return Do[NoBody, Envelope[[]T]](ctx, c, call, NoBody{})
+}
+
+// expandPath fills the {name} placeholders of path with the escaped params.
+func expandPath(path string, params map[string]string) string {
+	for name, value := range params {
+		path = strings.ReplaceAll(path, "{"+name+"}", url.PathEscape(value))
+	}
+
+	return path
+}
+
+// PageQuery builds the query of a paginated list; zero values are left out.
+func PageQuery(limit int, offset string, params map[string]string) url.Values {
+	query := url.Values{}
+	for key, value := range params {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+
+	if limit != 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+
+	if offset != "" {
+		query.Set("offset", offset)
+	}
+
+	return query
+}
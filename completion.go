@@ -0,0 +1,125 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cyber/test-project/services"
+)
+
+// completeCommand is the hidden command the completion script calls with the words typed so far.
+const completeCommand = "__complete"
+
+const bashCompletion = `_%[1]s() {
+	local IFS=$'\n'
+	COMPREPLY=($(compgen -W "$("${COMP_WORDS[0]}" %[2]s "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null)" -- "${COMP_WORDS[COMP_CWORD]}"))
+}
+complete -o default -F _%[1]s %[3]s
+`
+
+func printCompletion(args []string) int {
+	if len(args) > 0 && args[0] != "bash" {
+		fmt.Fprintf(os.Stderr, "unsupported shell %q, only bash is supported\n", args[0])
+		return 2
+	}
+
+	printBashCompletion(os.Stdout, filepath.Base(os.Args[0]))
+
+	return 0
+}
+
+func printBashCompletion(w io.Writer, program string) {
+	fmt.Fprintf(w, bashCompletion, strings.NewReplacer("-", "_", ".", "_").Replace(program), completeCommand, program)
+}
+
+func complete(words []string) int {
+	printCandidates(os.Stdout, words)
+
+	return 0
+}
+
+// printCandidates writes completion candidates for the last of words; the shell filters them by
+// prefix. Flag values are expected to be separated from the flag by a space.
+func printCandidates(w io.Writer, words []string) {
+	if len(words) <= 1 {
+		for _, cmd := range commands {
+			fmt.Fprintln(w, cmd.name)
+		}
+		fmt.Fprintln(w, "help")
+		return
+	}
+
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == words[0] {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		return
+	}
+
+	current := words[len(words)-1]
+	previous := words[len(words)-2]
+	if strings.HasPrefix(previous, "-") && !strings.Contains(previous, "=") {
+		if candidates, ok := flagValues(strings.TrimLeft(previous, "-"), words[1:]); ok {
+			for _, candidate := range candidates {
+				fmt.Fprintln(w, candidate)
+			}
+			return
+		}
+	}
+
+	if cmd.flags != nil && (strings.HasPrefix(current, "-") || current == "") {
+		cmd.flags().VisitAll(func(f *flag.Flag) {
+			fmt.Fprintln(w, "-"+f.Name)
+		})
+	}
+}
+
+// flagValues returns the candidates for the value of flag name, or false when the flag takes
+// free-form values such as paths.
+func flagValues(name string, args []string) ([]string, bool) {
+	switch name {
+	case "output":
+		return outputFormats, true
+	case "format":
+		return []string{services.ExportFormatJSON, services.ExportFormatNDJSON}, true
+	case "types":
+		return services.ResourceTypes, true
+	case "type", "gid":
+		flags, qf := newQueryFlags()
+		flags.SetOutput(io.Discard)
+		flags.Init("query", flag.ContinueOnError)
+		_ = flags.Parse(withoutLastFlag(args))
+
+		store, err := qf.store()
+		if err != nil {
+			return nil, true
+		}
+
+		if name == "gid" {
+			gids, _ := store.Gids(qf.resource)
+			return gids, true
+		}
+
+		types, _ := storedTypes(store)
+		return types, true
+	default:
+		return nil, false
+	}
+}
+
+// withoutLastFlag drops the flag being completed and the partial word after it, so the rest
+// parses cleanly.
+func withoutLastFlag(args []string) []string {
+	if len(args) < 2 {
+		return nil
+	}
+
+	return args[:len(args)-2]
+}
@@ -1,44 +1,273 @@
 package config
 
 import (
+	"net/url"
 	"time"
 
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Http            HttpConfig           `mapstructure:"http"`
-	ShutdownTimeout time.Duration        `mapstructure:"shutdown_timeout"`
-	Logging         LoggingConfig        `mapstructure:"logging"`
-	CircuitBreaker  CircuitBreakerConfig `mapstructure:"circuit_breaker"`
-	Asana           AsanaConfig          `mapstructure:"asana"`
-	DataDumper      DataDumperConfig     `mapstructure:"data_dumper"`
+	Http            HttpConfig           `mapstructure:"http" yaml:"http"`
+	ShutdownTimeout time.Duration        `mapstructure:"shutdown_timeout" yaml:"shutdown_timeout"`
+	ShutdownDelay   time.Duration        `mapstructure:"shutdown_delay" yaml:"shutdown_delay"`
+	Logging         LoggingConfig        `mapstructure:"logging" yaml:"logging"`
+	CircuitBreaker  CircuitBreakerConfig `mapstructure:"circuit_breaker" yaml:"circuit_breaker"`
+	Asana           AsanaConfig          `mapstructure:"asana" yaml:"asana"`
+	DataDumper      DataDumperConfig     `mapstructure:"data_dumper" yaml:"data_dumper"`
+	Health          HealthConfig         `mapstructure:"health" yaml:"health"`
+	Tracing         TracingConfig        `mapstructure:"tracing" yaml:"tracing"`
+	Auth            AuthConfig           `mapstructure:"auth" yaml:"auth"`
 }
 
 type HttpConfig struct {
-	Addr string `mapstructure:"addr"`
+	Addr           string               `mapstructure:"addr" yaml:"addr"`
+	RequestTimeout RequestTimeoutConfig `mapstructure:"request_timeout" yaml:"request_timeout"`
+	LegacyRoutes   LegacyRoutesConfig   `mapstructure:"legacy_routes" yaml:"legacy_routes"`
+}
+
+// RequestTimeoutConfig bounds the time spent handling inbound requests. Routes give route path
+// templates, e.g. /api/v1/users/{gid}, their own timeout; a route timeout of 0 disables it.
+type RequestTimeoutConfig struct {
+	Default time.Duration        `mapstructure:"default" yaml:"default"`
+	Routes  []RouteTimeoutConfig `mapstructure:"routes" yaml:"routes"`
+}
+
+// RouteTimeoutConfig is a list entry rather than a map key, since viper lowercases keys and splits
+// them on dots, which would mangle paths like /api/openapi.json.
+type RouteTimeoutConfig struct {
+	Path    string        `mapstructure:"path" yaml:"path"`
+	Timeout time.Duration `mapstructure:"timeout" yaml:"timeout"`
+}
+
+// LegacyRoutesConfig dates the deprecation of the unversioned verb-style routes, e.g. /api/users/get,
+// sent in their Deprecation and Sunset headers. Dates are in YYYY-MM-DD form.
+type LegacyRoutesConfig struct {
+	DeprecatedAt time.Time `mapstructure:"deprecated_at" yaml:"deprecated_at"`
+	Sunset       time.Time `mapstructure:"sunset" yaml:"sunset"`
+}
+
+// WithDefaults fills in the default dates of the legacy routes left unset.
+func (c LegacyRoutesConfig) WithDefaults() LegacyRoutesConfig {
+	if c.DeprecatedAt.IsZero() {
+		c.DeprecatedAt = defaultLegacyDeprecatedAt
+	}
+	if c.Sunset.IsZero() {
+		c.Sunset = defaultLegacySunset
+	}
+
+	return c
 }
 
 type LoggingConfig struct {
-	Level         string   `mapstructure:"level"`
-	Output        []string `mapstructure:"output"`
-	LogStackTrace bool     `mapstructure:"log_stack_trace"`
+	Level         string            `mapstructure:"level" yaml:"level"`
+	Levels        map[string]string `mapstructure:"levels" yaml:"levels"`
+	Output        []string          `mapstructure:"output" yaml:"output"`
+	Outputs       []LogOutputConfig `mapstructure:"outputs" yaml:"outputs"`
+	Sampling      LogSamplingConfig `mapstructure:"sampling" yaml:"sampling"`
+	LogStackTrace bool              `mapstructure:"log_stack_trace" yaml:"log_stack_trace"`
+	Redaction     RedactionConfig   `mapstructure:"redaction" yaml:"redaction"`
+}
+
+// AllOutputs returns the detailed outputs followed by plain `output` paths, which use the JSON encoding.
+func (c LoggingConfig) AllOutputs() []LogOutputConfig {
+	outputs := append([]LogOutputConfig(nil), c.Outputs...)
+	for _, path := range c.Output {
+		outputs = append(outputs, LogOutputConfig{Path: path, Encoding: LogEncodingJSON})
+	}
+
+	return outputs
+}
+
+const (
+	LogEncodingJSON    = "json"
+	LogEncodingConsole = "console"
+)
+
+type LogOutputConfig struct {
+	Path     string            `mapstructure:"path" yaml:"path"`
+	Encoding string            `mapstructure:"encoding" yaml:"encoding"`
+	Rotation LogRotationConfig `mapstructure:"rotation" yaml:"rotation"`
+}
+
+type LogRotationConfig struct {
+	MaxSizeMB  int           `mapstructure:"max_size_mb" yaml:"max_size_mb"`
+	Interval   time.Duration `mapstructure:"interval" yaml:"interval"`
+	MaxAge     time.Duration `mapstructure:"max_age" yaml:"max_age"`
+	MaxBackups int           `mapstructure:"max_backups" yaml:"max_backups"`
+	Compress   bool          `mapstructure:"compress" yaml:"compress"`
+}
+
+func (c LogRotationConfig) Enabled() bool {
+	return c.MaxSizeMB > 0 || c.Interval > 0
+}
+
+type LogSamplingConfig struct {
+	Tick   time.Duration                    `mapstructure:"tick" yaml:"tick"`
+	Levels map[string]LogSamplingRateConfig `mapstructure:"levels" yaml:"levels"`
+}
+
+type LogSamplingRateConfig struct {
+	Initial    int `mapstructure:"initial" yaml:"initial"`
+	Thereafter int `mapstructure:"thereafter" yaml:"thereafter"`
+}
+
+type RedactionConfig struct {
+	Headers     []string `mapstructure:"headers" yaml:"headers"`
+	QueryParams []string `mapstructure:"query_params" yaml:"query_params"`
+	JSONKeys    []string `mapstructure:"json_keys" yaml:"json_keys"`
+	Patterns    []string `mapstructure:"patterns" yaml:"patterns"`
 }
 
 type CircuitBreakerConfig struct {
-	Name        string        `mapstructure:"name"`
-	Timeout     time.Duration `mapstructure:"timeout"`
-	MaxRequests uint32        `mapstructure:"max_requests"`
-	MaxFailures uint32        `mapstructure:"max_failures"`
+	Name        string        `mapstructure:"name" yaml:"name"`
+	Timeout     time.Duration `mapstructure:"timeout" yaml:"timeout"`
+	MaxRequests uint32        `mapstructure:"max_requests" yaml:"max_requests"`
+	MaxFailures uint32        `mapstructure:"max_failures" yaml:"max_failures"`
 }
 
 type AsanaConfig struct {
-	BaseURL     string `mapstructure:"base_url"`
-	AccessToken string `mapstructure:"access_token"`
+	BaseURL     string           `mapstructure:"base_url" yaml:"base_url"`
+	AccessToken string           `mapstructure:"access_token" yaml:"access_token"`
+	RateLimit   RateLimitConfig  `mapstructure:"rate_limit" yaml:"rate_limit"`
+	Retry       RetryConfig      `mapstructure:"retry" yaml:"retry"`
+	Cassette    CassetteConfig   `mapstructure:"cassette" yaml:"cassette"`
+	Transport   TransportConfig  `mapstructure:"transport" yaml:"transport"`
+	Coalescing  CoalescingConfig `mapstructure:"coalescing" yaml:"coalescing"`
+	Hedging     HedgingConfig    `mapstructure:"hedging" yaml:"hedging"`
+}
+
+// CoalescingConfig lets identical in-flight GET requests, with the same token and query, share one
+// upstream call.
+type CoalescingConfig struct {
+	Enabled bool `mapstructure:"enabled" yaml:"enabled"`
+}
+
+// HedgingConfig sends a second attempt of a GET request of one of Resources when the first has not
+// answered within the Percentile latency of recent calls, clamped to MinDelay..MaxDelay. Nothing is
+// hedged until MinSamples latencies were observed.
+type HedgingConfig struct {
+	Enabled    bool          `mapstructure:"enabled" yaml:"enabled"`
+	Resources  []string      `mapstructure:"resources" yaml:"resources"`
+	Percentile float64       `mapstructure:"percentile" yaml:"percentile"`
+	MinDelay   time.Duration `mapstructure:"min_delay" yaml:"min_delay"`
+	MaxDelay   time.Duration `mapstructure:"max_delay" yaml:"max_delay"`
+	MinSamples int           `mapstructure:"min_samples" yaml:"min_samples"`
+}
+
+// TransportConfig tunes the HTTP client used for one upstream service. Timeout bounds a whole
+// request including reading the body; the other timeouts bound single connection phases.
+type TransportConfig struct {
+	Timeout               time.Duration `mapstructure:"timeout" yaml:"timeout"`
+	DialTimeout           time.Duration `mapstructure:"dial_timeout" yaml:"dial_timeout"`
+	TLSHandshakeTimeout   time.Duration `mapstructure:"tls_handshake_timeout" yaml:"tls_handshake_timeout"`
+	ResponseHeaderTimeout time.Duration `mapstructure:"response_header_timeout" yaml:"response_header_timeout"`
+	IdleConnTimeout       time.Duration `mapstructure:"idle_conn_timeout" yaml:"idle_conn_timeout"`
+	MaxIdleConnsPerHost   int           `mapstructure:"max_idle_conns_per_host" yaml:"max_idle_conns_per_host"`
+	DisableHTTP2          bool          `mapstructure:"disable_http2" yaml:"disable_http2"`
+	// ProxyURL overrides the HTTP_PROXY/HTTPS_PROXY/NO_PROXY environment variables.
+	ProxyURL string             `mapstructure:"proxy_url" yaml:"proxy_url"`
+	TLS      TransportTLSConfig `mapstructure:"tls" yaml:"tls"`
+}
+
+type TransportTLSConfig struct {
+	// CAFile is a PEM bundle trusted in addition to the system roots.
+	CAFile string `mapstructure:"ca_file" yaml:"ca_file"`
+	// CertFile and KeyFile are the PEM client certificate and key for mTLS.
+	CertFile   string `mapstructure:"cert_file" yaml:"cert_file"`
+	KeyFile    string `mapstructure:"key_file" yaml:"key_file"`
+	ServerName string `mapstructure:"server_name" yaml:"server_name"`
+}
+
+// RetryConfig controls retries of idempotent outbound requests. MaxAttempts counts the first
+// attempt, so 1 disables retries.
+type RetryConfig struct {
+	MaxAttempts    int           `mapstructure:"max_attempts" yaml:"max_attempts"`
+	InitialBackoff time.Duration `mapstructure:"initial_backoff" yaml:"initial_backoff"`
+	MaxBackoff     time.Duration `mapstructure:"max_backoff" yaml:"max_backoff"`
+	// MinRemainingBudget is the request budget that must be left after the backoff for a retry to
+	// be attempted.
+	MinRemainingBudget time.Duration `mapstructure:"min_remaining_budget" yaml:"min_remaining_budget"`
+}
+
+const (
+	CassetteModeOff    = "off"
+	CassetteModeRecord = "record"
+	CassetteModeReplay = "replay"
+)
+
+const (
+	CassetteMatchMethod = "method"
+	CassetteMatchPath   = "path"
+	CassetteMatchQuery  = "query"
+	CassetteMatchBody   = "body"
+)
+
+// CassetteConfig records outbound requests to a cassette file or replays them from it instead of
+// calling the upstream service. The file format follows the extension: .json or .yaml/.yml.
+type CassetteConfig struct {
+	Mode  string   `mapstructure:"mode" yaml:"mode"`
+	Path  string   `mapstructure:"path" yaml:"path"`
+	Match []string `mapstructure:"match" yaml:"match"`
+}
+
+type RateLimitConfig struct {
+	RequestsPerSecond float64 `mapstructure:"requests_per_second" yaml:"requests_per_second"`
+	Burst             int     `mapstructure:"burst" yaml:"burst"`
 }
 
 type DataDumperConfig struct {
-	Path string `mapstructure:"path"`
+	Path string `mapstructure:"path" yaml:"path"`
+}
+
+type HealthConfig struct {
+	CheckTimeout time.Duration          `mapstructure:"check_timeout" yaml:"check_timeout"`
+	AsanaProbe   HealthAsanaProbeConfig `mapstructure:"asana_probe" yaml:"asana_probe"`
+}
+
+type HealthAsanaProbeConfig struct {
+	Enabled  bool          `mapstructure:"enabled" yaml:"enabled"`
+	CacheTTL time.Duration `mapstructure:"cache_ttl" yaml:"cache_ttl"`
+}
+
+type TracingConfig struct {
+	Exporter    string `mapstructure:"exporter" yaml:"exporter"`
+	ServiceName string `mapstructure:"service_name" yaml:"service_name"`
+	// SampleRatio is a pointer so that an explicit 0, sampling nothing, is told apart from unset.
+	SampleRatio  *float64 `mapstructure:"sample_ratio" yaml:"sample_ratio"`
+	OTLPEndpoint string   `mapstructure:"otlp_endpoint" yaml:"otlp_endpoint"`
+	OTLPInsecure bool     `mapstructure:"otlp_insecure" yaml:"otlp_insecure"`
+}
+
+// AuthConfig protects the API with static API keys, sent in the X-API-Key header, and JWT bearer
+// tokens.
+type AuthConfig struct {
+	Enabled bool           `mapstructure:"enabled" yaml:"enabled"`
+	APIKeys []APIKeyConfig `mapstructure:"api_keys" yaml:"api_keys"`
+	JWT     JWTConfig      `mapstructure:"jwt" yaml:"jwt"`
+}
+
+type APIKeyConfig struct {
+	Name string `mapstructure:"name" yaml:"name"`
+	// Hash is "sha256:" followed by the hex SHA-256 digest of the key, as printed by `test_app hash-api-key`.
+	Hash   string   `mapstructure:"hash" yaml:"hash"`
+	Scopes []string `mapstructure:"scopes" yaml:"scopes"`
+}
+
+const (
+	JWTAlgorithmHS256 = "HS256"
+	JWTAlgorithmRS256 = "RS256"
+)
+
+// JWTConfig accepts tokens signed with a key of the JWKS file: "oct" keys for HS256 and "RSA" keys
+// for RS256. Issuer and Audience are checked when set.
+type JWTConfig struct {
+	JWKSFile   string        `mapstructure:"jwks_file" yaml:"jwks_file"`
+	Algorithms []string      `mapstructure:"algorithms" yaml:"algorithms"`
+	Issuer     string        `mapstructure:"issuer" yaml:"issuer"`
+	Audience   string        `mapstructure:"audience" yaml:"audience"`
+	Leeway     time.Duration `mapstructure:"leeway" yaml:"leeway"`
 }
 
 func ReadConfig(configPath string) (Config, error) {
@@ -51,10 +280,47 @@ func ReadConfig(configPath string) (Config, error) {
 
 	var config Config
 
-	err = viperConfig.Unmarshal(&config)
+	err = viperConfig.Unmarshal(&config, viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+		mapstructure.StringToTimeHookFunc(time.DateOnly),
+	)))
+	if err != nil {
+		return Config{}, err
+	}
+
+	err = config.Validate()
 	if err != nil {
 		return Config{}, err
 	}
 
 	return config, nil
 }
+
+// Masked returns a copy of the config that is safe to print, with secrets replaced.
+func (c Config) Masked() Config {
+	c.Asana.AccessToken = MaskSecret(c.Asana.AccessToken)
+	c.Asana.Transport.ProxyURL = maskURL(c.Asana.Transport.ProxyURL)
+	c.Logging.Output = append([]string(nil), c.Logging.Output...)
+
+	return c
+}
+
+func maskURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	return u.Redacted()
+}
+
+func MaskSecret(secret string) string {
+	const visible = 4
+
+	if len(secret) <= visible*2 {
+		return "****"
+	}
+
+	return "****" + secret[len(secret)-visible:]
+}
@@ -0,0 +1,520 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"net"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	defaultHttpAddr              = "0.0.0.0:8001"
+	defaultShutdownTimeout       = 5 * time.Second
+	defaultLogLevel              = "info"
+	defaultLogOutput             = "stdout"
+	defaultSamplingTick          = time.Second
+	defaultLogMaxSizeMB          = 100
+	defaultAsanaBaseURL          = "https://app.asana.com"
+	defaultRetryAttempts         = 1
+	defaultInitialBackoff        = 200 * time.Millisecond
+	defaultMaxBackoff            = 5 * time.Second
+	defaultMinRetryBudget        = 250 * time.Millisecond
+	defaultRequestTimeout        = 30 * time.Second
+	defaultTransportTimeout      = 30 * time.Second
+	defaultDialTimeout           = 5 * time.Second
+	defaultTLSHandshakeTimeout   = 5 * time.Second
+	defaultResponseHeaderTimeout = 10 * time.Second
+	defaultIdleConnTimeout       = 90 * time.Second
+	defaultMaxIdleConnsPerHost   = 10
+	defaultHedgingPercentile     = 0.95
+	defaultHedgingMinDelay       = 10 * time.Millisecond
+	defaultHedgingMaxDelay       = time.Second
+	defaultHedgingMinSamples     = 20
+	defaultHealthTimeout         = 2 * time.Second
+	defaultAsanaProbeTTL         = time.Minute
+	defaultTracingExporter       = "none"
+	defaultServiceName           = "test-project"
+	defaultSampleRatio           = 1
+	defaultOTLPEndpoint          = "localhost:4318"
+)
+
+var (
+	tracingExporters = []string{"none", "stdout", "otlp"}
+
+	cassetteModes   = []string{CassetteModeOff, CassetteModeRecord, CassetteModeReplay}
+	cassetteMatches = []string{CassetteMatchMethod, CassetteMatchPath, CassetteMatchQuery, CassetteMatchBody}
+	cassetteFormats = []string{".json", ".yaml", ".yml"}
+
+	defaultCassetteMatch = []string{CassetteMatchMethod, CassetteMatchPath, CassetteMatchQuery}
+
+	hedgingResources = []string{"users", "projects"}
+
+	jwtAlgorithms        = []string{JWTAlgorithmHS256, JWTAlgorithmRS256}
+	apiKeyHash           = regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)
+	defaultJWTAlgorithms = []string{JWTAlgorithmRS256}
+
+	defaultLegacyDeprecatedAt = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	defaultLegacySunset       = time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC)
+
+	defaultRedactedHeaders     = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+	defaultRedactedQueryParams = []string{"access_token", "token", "api_key"}
+	defaultRedactedJSONKeys    = []string{"email", "access_token", "refresh_token", "token", "password", "secret"}
+	defaultRedactedPatterns    = []string{`(?i)bearer\s+[^\s"]+`}
+)
+
+type FieldError struct {
+	Key     string
+	Message string
+}
+
+type ErrInvalidConfig struct {
+	Fields []FieldError
+}
+
+func (e ErrInvalidConfig) Error() string {
+	var sb strings.Builder
+	sb.WriteString("invalid configuration:")
+	for _, field := range e.Fields {
+		sb.WriteString("\n  - ")
+		sb.WriteString(field.Key)
+		sb.WriteString(": ")
+		sb.WriteString(field.Message)
+	}
+
+	return sb.String()
+}
+
+type validator struct {
+	fields []FieldError
+}
+
+func (v *validator) fail(key string, format string, args ...any) {
+	v.fields = append(v.fields, FieldError{Key: key, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+
+	return ErrInvalidConfig{Fields: v.fields}
+}
+
+// Validate fills in defaults for optional settings and reports every invalid or missing
+// required setting at once, so that all of them can be fixed in a single pass.
+func (c *Config) Validate() error {
+	c.applyDefaults()
+
+	v := &validator{}
+
+	if _, _, err := net.SplitHostPort(c.Http.Addr); err != nil {
+		v.fail("http.addr", "must be in host:port form, got %q", c.Http.Addr)
+	}
+
+	if c.Http.RequestTimeout.Default < 0 {
+		v.fail("http.request_timeout.default", "must not be negative, got %s", c.Http.RequestTimeout.Default)
+	}
+
+	routePaths := map[string]bool{}
+	for i, route := range c.Http.RequestTimeout.Routes {
+		key := fmt.Sprintf("http.request_timeout.routes[%d]", i)
+		if !strings.HasPrefix(route.Path, "/") {
+			v.fail(key+".path", "must be a route path starting with /, got %q", route.Path)
+		} else if routePaths[route.Path] {
+			v.fail(key+".path", "duplicate path %q", route.Path)
+		}
+		routePaths[route.Path] = true
+
+		if route.Timeout < 0 {
+			v.fail(key+".timeout", "must not be negative, got %s", route.Timeout)
+		}
+	}
+
+	if legacy := c.Http.LegacyRoutes; legacy.Sunset.Before(legacy.DeprecatedAt) {
+		v.fail("http.legacy_routes.sunset", "must not be before deprecated_at %s, got %s",
+			legacy.DeprecatedAt.Format(time.DateOnly), legacy.Sunset.Format(time.DateOnly))
+	}
+
+	if c.ShutdownTimeout < 0 {
+		v.fail("shutdown_timeout", "must not be negative, got %s", c.ShutdownTimeout)
+	}
+
+	if c.ShutdownDelay < 0 {
+		v.fail("shutdown_delay", "must not be negative, got %s", c.ShutdownDelay)
+	}
+
+	if _, err := zapcore.ParseLevel(c.Logging.Level); err != nil {
+		v.fail("logging.level", "unknown level %q", c.Logging.Level)
+	}
+
+	for _, component := range slices.Sorted(maps.Keys(c.Logging.Levels)) {
+		level := c.Logging.Levels[component]
+		if _, err := zapcore.ParseLevel(level); err != nil {
+			v.fail("logging.levels."+component, "unknown level %q", level)
+		}
+	}
+
+	for i, output := range c.Logging.Outputs {
+		key := fmt.Sprintf("logging.outputs[%d]", i)
+		if output.Path == "" {
+			v.fail(key+".path", "is required")
+		}
+
+		if output.Encoding != LogEncodingJSON && output.Encoding != LogEncodingConsole {
+			v.fail(key+".encoding", "must be %s or %s, got %q", LogEncodingJSON, LogEncodingConsole, output.Encoding)
+		}
+
+		rotation := output.Rotation
+		if rotation.Enabled() && (output.Path == "stdout" || output.Path == "stderr") {
+			v.fail(key+".rotation", "is only supported for file outputs")
+		}
+
+		if rotation.MaxSizeMB < 0 || rotation.MaxBackups < 0 || rotation.Interval < 0 || rotation.MaxAge < 0 {
+			v.fail(key+".rotation", "values must not be negative")
+		}
+	}
+
+	if c.Logging.Sampling.Tick < 0 {
+		v.fail("logging.sampling.tick", "must not be negative, got %s", c.Logging.Sampling.Tick)
+	}
+
+	for _, level := range slices.Sorted(maps.Keys(c.Logging.Sampling.Levels)) {
+		if _, err := zapcore.ParseLevel(level); err != nil {
+			v.fail("logging.sampling.levels."+level, "unknown level %q", level)
+		}
+
+		rate := c.Logging.Sampling.Levels[level]
+		if rate.Initial < 0 || rate.Thereafter < 0 {
+			v.fail("logging.sampling.levels."+level, "values must not be negative")
+		}
+	}
+
+	for i, pattern := range c.Logging.Redaction.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			v.fail(fmt.Sprintf("logging.redaction.patterns[%d]", i), "invalid pattern: %v", err)
+		}
+	}
+
+	if c.CircuitBreaker.Timeout < 0 {
+		v.fail("circuit_breaker.timeout", "must not be negative, got %s", c.CircuitBreaker.Timeout)
+	}
+
+	baseURL, err := url.Parse(c.Asana.BaseURL)
+	if err != nil || (baseURL.Scheme != "http" && baseURL.Scheme != "https") || baseURL.Host == "" {
+		v.fail("asana.base_url", "must be an absolute http(s) URL, got %q", c.Asana.BaseURL)
+	}
+
+	if c.Asana.RateLimit.RequestsPerSecond < 0 {
+		v.fail("asana.rate_limit.requests_per_second", "must not be negative, got %v", c.Asana.RateLimit.RequestsPerSecond)
+	}
+
+	if c.Asana.RateLimit.Burst < 0 {
+		v.fail("asana.rate_limit.burst", "must not be negative, got %d", c.Asana.RateLimit.Burst)
+	}
+
+	if c.Asana.Retry.MaxAttempts < 1 {
+		v.fail("asana.retry.max_attempts", "must be at least 1, got %d", c.Asana.Retry.MaxAttempts)
+	}
+
+	if c.Asana.Retry.InitialBackoff < 0 || c.Asana.Retry.MaxBackoff < 0 || c.Asana.Retry.MinRemainingBudget < 0 {
+		v.fail("asana.retry", "backoffs must not be negative")
+	} else if c.Asana.Retry.InitialBackoff > c.Asana.Retry.MaxBackoff {
+		v.fail("asana.retry.initial_backoff", "must not exceed max_backoff %s, got %s", c.Asana.Retry.MaxBackoff, c.Asana.Retry.InitialBackoff)
+	}
+
+	validateTransport(v, "asana.transport", c.Asana.Transport)
+
+	if !slices.Contains(cassetteModes, c.Asana.Cassette.Mode) {
+		v.fail("asana.cassette.mode", "must be one of %s, got %q", strings.Join(cassetteModes, ", "), c.Asana.Cassette.Mode)
+	}
+
+	if c.Asana.Cassette.Mode != CassetteModeOff {
+		if c.Asana.Cassette.Path == "" {
+			v.fail("asana.cassette.path", "is required when mode is %s", c.Asana.Cassette.Mode)
+		} else if !slices.Contains(cassetteFormats, strings.ToLower(filepath.Ext(c.Asana.Cassette.Path))) {
+			v.fail("asana.cassette.path", "must end with one of %s, got %q", strings.Join(cassetteFormats, ", "), c.Asana.Cassette.Path)
+		}
+	}
+
+	for i, match := range c.Asana.Cassette.Match {
+		if !slices.Contains(cassetteMatches, match) {
+			v.fail(fmt.Sprintf("asana.cassette.match[%d]", i), "must be one of %s, got %q", strings.Join(cassetteMatches, ", "), match)
+		}
+	}
+
+	validateHedging(v, "asana.hedging", c.Asana.Hedging)
+
+	if c.Asana.AccessToken == "" {
+		v.fail("asana.access_token", "is required")
+	}
+
+	if c.DataDumper.Path == "" {
+		v.fail("data_dumper.path", "is required")
+	}
+
+	if c.Health.CheckTimeout < 0 {
+		v.fail("health.check_timeout", "must not be negative, got %s", c.Health.CheckTimeout)
+	}
+
+	if c.Health.AsanaProbe.CacheTTL < 0 {
+		v.fail("health.asana_probe.cache_ttl", "must not be negative, got %s", c.Health.AsanaProbe.CacheTTL)
+	}
+
+	if !slices.Contains(tracingExporters, c.Tracing.Exporter) {
+		v.fail("tracing.exporter", "must be one of %s, got %q", strings.Join(tracingExporters, ", "), c.Tracing.Exporter)
+	}
+
+	if *c.Tracing.SampleRatio < 0 || *c.Tracing.SampleRatio > 1 {
+		v.fail("tracing.sample_ratio", "must be between 0 and 1, got %v", *c.Tracing.SampleRatio)
+	}
+
+	validateAuth(v, "auth", c.Auth)
+
+	return v.err()
+}
+
+func (c *Config) applyDefaults() {
+	if c.Http.Addr == "" {
+		c.Http.Addr = defaultHttpAddr
+	}
+
+	if c.Http.RequestTimeout.Default == 0 {
+		c.Http.RequestTimeout.Default = defaultRequestTimeout
+	}
+
+	c.Http.LegacyRoutes = c.Http.LegacyRoutes.WithDefaults()
+
+	if c.ShutdownTimeout == 0 {
+		c.ShutdownTimeout = defaultShutdownTimeout
+	}
+
+	if c.Logging.Level == "" {
+		c.Logging.Level = defaultLogLevel
+	}
+
+	if len(c.Logging.Output) == 0 && len(c.Logging.Outputs) == 0 {
+		c.Logging.Output = []string{defaultLogOutput}
+	}
+
+	for i := range c.Logging.Outputs {
+		if c.Logging.Outputs[i].Encoding == "" {
+			c.Logging.Outputs[i].Encoding = LogEncodingJSON
+		}
+
+		// Files are always rotated by size as well, so interval-only rotation gets an explicit limit.
+		rotation := &c.Logging.Outputs[i].Rotation
+		if rotation.Interval > 0 && rotation.MaxSizeMB == 0 {
+			rotation.MaxSizeMB = defaultLogMaxSizeMB
+		}
+	}
+
+	if c.Logging.Sampling.Tick == 0 {
+		c.Logging.Sampling.Tick = defaultSamplingTick
+	}
+
+	if c.Logging.Redaction.Headers == nil {
+		c.Logging.Redaction.Headers = slices.Clone(defaultRedactedHeaders)
+	}
+
+	if c.Logging.Redaction.QueryParams == nil {
+		c.Logging.Redaction.QueryParams = slices.Clone(defaultRedactedQueryParams)
+	}
+
+	if c.Logging.Redaction.JSONKeys == nil {
+		c.Logging.Redaction.JSONKeys = slices.Clone(defaultRedactedJSONKeys)
+	}
+
+	if c.Logging.Redaction.Patterns == nil {
+		c.Logging.Redaction.Patterns = slices.Clone(defaultRedactedPatterns)
+	}
+
+	if c.Asana.BaseURL == "" {
+		c.Asana.BaseURL = defaultAsanaBaseURL
+	}
+
+	if c.Asana.Retry.MaxAttempts == 0 {
+		c.Asana.Retry.MaxAttempts = defaultRetryAttempts
+	}
+
+	if c.Asana.Retry.InitialBackoff == 0 {
+		c.Asana.Retry.InitialBackoff = defaultInitialBackoff
+	}
+
+	if c.Asana.Retry.MaxBackoff == 0 {
+		c.Asana.Retry.MaxBackoff = defaultMaxBackoff
+	}
+
+	if c.Asana.Retry.MinRemainingBudget == 0 {
+		c.Asana.Retry.MinRemainingBudget = defaultMinRetryBudget
+	}
+
+	applyTransportDefaults(&c.Asana.Transport)
+
+	if c.Asana.Cassette.Mode == "" {
+		c.Asana.Cassette.Mode = CassetteModeOff
+	}
+
+	if len(c.Asana.Cassette.Match) == 0 {
+		c.Asana.Cassette.Match = slices.Clone(defaultCassetteMatch)
+	}
+
+	applyHedgingDefaults(&c.Asana.Hedging)
+
+	if c.Asana.RateLimit.RequestsPerSecond > 0 && c.Asana.RateLimit.Burst == 0 {
+		c.Asana.RateLimit.Burst = 1
+	}
+
+	if c.Health.CheckTimeout == 0 {
+		c.Health.CheckTimeout = defaultHealthTimeout
+	}
+
+	if c.Health.AsanaProbe.CacheTTL == 0 {
+		c.Health.AsanaProbe.CacheTTL = defaultAsanaProbeTTL
+	}
+
+	if c.Tracing.Exporter == "" {
+		c.Tracing.Exporter = defaultTracingExporter
+	}
+
+	if c.Tracing.ServiceName == "" {
+		c.Tracing.ServiceName = defaultServiceName
+	}
+
+	if c.Tracing.SampleRatio == nil {
+		sampleRatio := float64(defaultSampleRatio)
+		c.Tracing.SampleRatio = &sampleRatio
+	}
+
+	if c.Tracing.OTLPEndpoint == "" {
+		c.Tracing.OTLPEndpoint = defaultOTLPEndpoint
+	}
+
+	if len(c.Auth.JWT.Algorithms) == 0 {
+		c.Auth.JWT.Algorithms = slices.Clone(defaultJWTAlgorithms)
+	}
+}
+
+func validateTransport(v *validator, key string, t TransportConfig) {
+	if t.Timeout < 0 || t.DialTimeout < 0 || t.TLSHandshakeTimeout < 0 || t.ResponseHeaderTimeout < 0 || t.IdleConnTimeout < 0 {
+		v.fail(key, "timeouts must not be negative")
+	}
+
+	if t.MaxIdleConnsPerHost < 0 {
+		v.fail(key+".max_idle_conns_per_host", "must not be negative, got %d", t.MaxIdleConnsPerHost)
+	}
+
+	if t.ProxyURL != "" {
+		proxyURL, err := url.Parse(t.ProxyURL)
+		if err != nil || !slices.Contains([]string{"http", "https", "socks5"}, proxyURL.Scheme) || proxyURL.Host == "" {
+			v.fail(key+".proxy_url", "must be an absolute http(s) or socks5 URL, got %q", maskURL(t.ProxyURL))
+		}
+	}
+
+	if (t.TLS.CertFile == "") != (t.TLS.KeyFile == "") {
+		v.fail(key+".tls", "cert_file and key_file must be set together")
+	}
+}
+
+func applyTransportDefaults(t *TransportConfig) {
+	if t.Timeout == 0 {
+		t.Timeout = defaultTransportTimeout
+	}
+
+	if t.DialTimeout == 0 {
+		t.DialTimeout = defaultDialTimeout
+	}
+
+	if t.TLSHandshakeTimeout == 0 {
+		t.TLSHandshakeTimeout = defaultTLSHandshakeTimeout
+	}
+
+	if t.ResponseHeaderTimeout == 0 {
+		t.ResponseHeaderTimeout = defaultResponseHeaderTimeout
+	}
+
+	if t.IdleConnTimeout == 0 {
+		t.IdleConnTimeout = defaultIdleConnTimeout
+	}
+
+	if t.MaxIdleConnsPerHost == 0 {
+		t.MaxIdleConnsPerHost = defaultMaxIdleConnsPerHost
+	}
+}
+
+func validateHedging(v *validator, key string, h HedgingConfig) {
+	if h.Percentile <= 0 || h.Percentile >= 1 {
+		v.fail(key+".percentile", "must be between 0 and 1, got %v", h.Percentile)
+	}
+
+	if h.MinDelay < 0 || h.MaxDelay < 0 {
+		v.fail(key, "delays must not be negative")
+	} else if h.MinDelay > h.MaxDelay {
+		v.fail(key+".min_delay", "must not exceed max_delay %s, got %s", h.MaxDelay, h.MinDelay)
+	}
+
+	if h.MinSamples < 1 {
+		v.fail(key+".min_samples", "must be at least 1, got %d", h.MinSamples)
+	}
+
+	for i, resource := range h.Resources {
+		if !slices.Contains(hedgingResources, resource) {
+			v.fail(fmt.Sprintf("%s.resources[%d]", key, i), "must be one of %s, got %q", strings.Join(hedgingResources, ", "), resource)
+		}
+	}
+}
+
+func applyHedgingDefaults(h *HedgingConfig) {
+	if h.Percentile == 0 {
+		h.Percentile = defaultHedgingPercentile
+	}
+
+	if h.MinDelay == 0 {
+		h.MinDelay = defaultHedgingMinDelay
+	}
+
+	if h.MaxDelay == 0 {
+		h.MaxDelay = defaultHedgingMaxDelay
+	}
+
+	if h.MinSamples == 0 {
+		h.MinSamples = defaultHedgingMinSamples
+	}
+}
+
+func validateAuth(v *validator, key string, a AuthConfig) {
+	if a.Enabled && len(a.APIKeys) == 0 && a.JWT.JWKSFile == "" {
+		v.fail(key, "api_keys or jwt.jwks_file is required when enabled")
+	}
+
+	names := map[string]bool{}
+	for i, apiKey := range a.APIKeys {
+		apiKeyKey := fmt.Sprintf("%s.api_keys[%d]", key, i)
+		if apiKey.Name == "" {
+			v.fail(apiKeyKey+".name", "is required")
+		} else if names[apiKey.Name] {
+			v.fail(apiKeyKey+".name", "duplicate name %q", apiKey.Name)
+		}
+		names[apiKey.Name] = true
+
+		if !apiKeyHash.MatchString(apiKey.Hash) {
+			v.fail(apiKeyKey+".hash", "must be sha256: followed by 64 lowercase hex digits")
+		}
+	}
+
+	for i, algorithm := range a.JWT.Algorithms {
+		if !slices.Contains(jwtAlgorithms, algorithm) {
+			v.fail(fmt.Sprintf("%s.jwt.algorithms[%d]", key, i), "must be one of %s, got %q", strings.Join(jwtAlgorithms, ", "), algorithm)
+		}
+	}
+
+	if a.JWT.Leeway < 0 {
+		v.fail(key+".jwt.leeway", "must not be negative, got %s", a.JWT.Leeway)
+	}
+}
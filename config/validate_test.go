@@ -0,0 +1,232 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func readTestConfig(t *testing.T, yaml string) (Config, error) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(path, []byte(yaml), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return ReadConfig(path)
+}
+
+const requiredConfig = `
+asana:
+  access_token: token
+data_dumper:
+  path: ./dumps
+`
+
+func TestReadConfigSampleRatio(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want float64
+	}{
+		{name: "unset", yaml: "", want: defaultSampleRatio},
+		{name: "zero", yaml: "tracing:\n  sample_ratio: 0\n", want: 0},
+		{name: "ratio", yaml: "tracing:\n  sample_ratio: 0.25\n", want: 0.25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := readTestConfig(t, requiredConfig+tt.yaml)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if *cfg.Tracing.SampleRatio != tt.want {
+				t.Errorf("sample_ratio = %v, want %v", *cfg.Tracing.SampleRatio, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadConfigRouteTimeouts(t *testing.T) {
+	cfg, err := readTestConfig(t, requiredConfig+`
+http:
+  request_timeout:
+    routes:
+      - path: /api/openapi.json
+        timeout: 2s
+      - path: /api/v1/users/{gid}
+        timeout: 0s
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []RouteTimeoutConfig{
+		{Path: "/api/openapi.json", Timeout: 2 * time.Second},
+		{Path: "/api/v1/users/{gid}", Timeout: 0},
+	}
+	if len(cfg.Http.RequestTimeout.Routes) != len(want) {
+		t.Fatalf("routes = %v, want %v", cfg.Http.RequestTimeout.Routes, want)
+	}
+	for i, route := range cfg.Http.RequestTimeout.Routes {
+		if route != want[i] {
+			t.Errorf("routes[%d] = %v, want %v", i, route, want[i])
+		}
+	}
+}
+
+func TestValidateRouteTimeouts(t *testing.T) {
+	tests := []struct {
+		name   string
+		routes []RouteTimeoutConfig
+		key    string
+	}{
+		{name: "relative path", routes: []RouteTimeoutConfig{{Path: "api/v1/users"}}, key: "http.request_timeout.routes[0].path"},
+		{name: "duplicate path", routes: []RouteTimeoutConfig{{Path: "/api/v1/users"}, {Path: "/api/v1/users"}}, key: "http.request_timeout.routes[1].path"},
+		{name: "negative timeout", routes: []RouteTimeoutConfig{{Path: "/api/v1/users", Timeout: -time.Second}}, key: "http.request_timeout.routes[0].timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				Http:       HttpConfig{RequestTimeout: RequestTimeoutConfig{Routes: tt.routes}},
+				Asana:      AsanaConfig{AccessToken: "token"},
+				DataDumper: DataDumperConfig{Path: "./dumps"},
+			}
+
+			err := cfg.Validate()
+			invalid, ok := err.(ErrInvalidConfig)
+			if !ok || len(invalid.Fields) != 1 || invalid.Fields[0].Key != tt.key {
+				t.Errorf("Validate() = %v, want a single error for %s", err, tt.key)
+			}
+		})
+	}
+}
+
+func TestApplyDefaultsCopiesSlices(t *testing.T) {
+	var first, second Config
+	first.applyDefaults()
+	second.applyDefaults()
+
+	first.Asana.Cassette.Match[0] = "changed"
+	first.Auth.JWT.Algorithms[0] = "changed"
+	first.Logging.Redaction.Headers[0] = "changed"
+
+	if second.Asana.Cassette.Match[0] == "changed" || second.Auth.JWT.Algorithms[0] == "changed" ||
+		second.Logging.Redaction.Headers[0] == "changed" {
+		t.Error("configs share their default slices")
+	}
+}
+
+func TestReadConfigRotationMaxSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		rotation string
+		want     int
+	}{
+		{name: "interval only", rotation: "interval: 24h", want: defaultLogMaxSizeMB},
+		{name: "interval and size", rotation: "interval: 24h, max_size_mb: 10", want: 10},
+		{name: "size only", rotation: "max_size_mb: 10", want: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := readTestConfig(t, requiredConfig+`
+logging:
+  outputs:
+    - path: ./app.log
+      rotation: {`+tt.rotation+`}
+`)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := cfg.Logging.Outputs[0].Rotation.MaxSizeMB; got != tt.want {
+				t.Errorf("max_size_mb = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadConfigLegacyRoutes(t *testing.T) {
+	tests := []struct {
+		name             string
+		yaml             string
+		wantDeprecatedAt time.Time
+		wantSunset       time.Time
+		wantErr          bool
+	}{
+		{name: "unset", wantDeprecatedAt: defaultLegacyDeprecatedAt, wantSunset: defaultLegacySunset},
+		{
+			name:             "dates",
+			yaml:             "http:\n  legacy_routes:\n    deprecated_at: 2026-11-01\n    sunset: \"2027-06-30\"\n",
+			wantDeprecatedAt: time.Date(2026, time.November, 1, 0, 0, 0, 0, time.UTC),
+			wantSunset:       time.Date(2027, time.June, 30, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:             "sunset only",
+			yaml:             "http:\n  legacy_routes:\n    sunset: 2027-01-31\n",
+			wantDeprecatedAt: defaultLegacyDeprecatedAt,
+			wantSunset:       time.Date(2027, time.January, 31, 0, 0, 0, 0, time.UTC),
+		},
+		{name: "sunset before deprecation", yaml: "http:\n  legacy_routes:\n    sunset: 2026-01-01\n", wantErr: true},
+		{name: "not a date", yaml: "http:\n  legacy_routes:\n    sunset: next spring\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := readTestConfig(t, requiredConfig+tt.yaml)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadConfig() error = %v, want error %t", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			legacy := cfg.Http.LegacyRoutes
+			if !legacy.DeprecatedAt.Equal(tt.wantDeprecatedAt) || !legacy.Sunset.Equal(tt.wantSunset) {
+				t.Errorf("legacy_routes = %v to %v, want %v to %v", legacy.DeprecatedAt, legacy.Sunset, tt.wantDeprecatedAt, tt.wantSunset)
+			}
+		})
+	}
+}
+
+func TestReadConfigShutdownDelay(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "unset", want: 0},
+		{name: "delay", yaml: "shutdown_delay: 10s\n", want: 10 * time.Second},
+		{name: "negative", yaml: "shutdown_delay: -1s\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := readTestConfig(t, requiredConfig+tt.yaml)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadConfig() error = %v, want error %t", err, tt.wantErr)
+			}
+			if err == nil && cfg.ShutdownDelay != tt.want {
+				t.Errorf("shutdown_delay = %s, want %s", cfg.ShutdownDelay, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadConfigSamplingDefaultsToNone(t *testing.T) {
+	cfg, err := readTestConfig(t, requiredConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(cfg.Logging.Sampling.Levels) != 0 {
+		t.Errorf("sampled levels = %v, want none, so that every access log line is kept", cfg.Logging.Sampling.Levels)
+	}
+}
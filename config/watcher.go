@@ -0,0 +1,174 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+type ChangeHandler func(prev, next Config)
+
+type ErrorHandler func(err error)
+
+// Watcher keeps the current configuration and re-reads it when the file changes or Reload is called.
+// A new configuration that fails validation is rejected and the previous one stays in effect.
+type Watcher struct {
+	path     string
+	reloadMu sync.Mutex
+	mu       sync.Mutex
+	current  Config
+	onChange []ChangeHandler
+	onError  ErrorHandler
+
+	fsWatcher *fsnotify.Watcher
+	watchDone chan struct{}
+}
+
+func NewWatcher(configPath string) (*Watcher, error) {
+	cfg, err := ReadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Watcher{
+		path:    configPath,
+		current: cfg,
+		onError: func(error) {},
+	}, nil
+}
+
+func (w *Watcher) Config() Config {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.current
+}
+
+func (w *Watcher) OnChange(handler ChangeHandler) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.onChange = append(w.onChange, handler)
+}
+
+func (w *Watcher) OnError(handler ErrorHandler) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.onError = handler
+}
+
+// Watch reloads the configuration whenever the file is written or replaced, until Stop is called.
+// The directory is watched rather than the file, so that editors that replace the file are followed.
+// Any event in the directory that changes the resolved target of the path reloads it too, which
+// follows config maps, where an update swaps a ..data symlink and no event names the file itself.
+func (w *Watcher) Watch() error {
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	err = fsWatcher.Add(filepath.Dir(w.path))
+	if err != nil {
+		_ = fsWatcher.Close()
+		return err
+	}
+
+	target, _ := filepath.EvalSymlinks(w.path)
+
+	done := make(chan struct{})
+	w.mu.Lock()
+	w.fsWatcher, w.watchDone = fsWatcher, done
+	w.mu.Unlock()
+
+	go func() {
+		defer close(done)
+
+		for {
+			select {
+			case event, ok := <-fsWatcher.Events:
+				if !ok {
+					return
+				}
+
+				written := filepath.Clean(event.Name) == filepath.Clean(w.path) && event.Has(fsnotify.Write|fsnotify.Create)
+
+				// The target is missing for a moment while a file is replaced; the event that
+				// recreates it reloads.
+				swapped := false
+				if next, err := filepath.EvalSymlinks(w.path); err == nil && next != target {
+					target, swapped = next, true
+				}
+
+				if written || swapped {
+					w.reportError(w.Reload())
+				}
+			case err, ok := <-fsWatcher.Errors:
+				if !ok {
+					return
+				}
+
+				w.reportError(err)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop stops watching the file. It is a no-op if Watch was not called.
+func (w *Watcher) Stop() error {
+	w.mu.Lock()
+	fsWatcher, done := w.fsWatcher, w.watchDone
+	w.fsWatcher, w.watchDone = nil, nil
+	w.mu.Unlock()
+
+	if fsWatcher == nil {
+		return nil
+	}
+
+	err := fsWatcher.Close()
+	<-done
+
+	return err
+}
+
+func (w *Watcher) reportError(err error) {
+	if err == nil {
+		return
+	}
+
+	w.mu.Lock()
+	onError := w.onError
+	w.mu.Unlock()
+
+	onError(err)
+}
+
+func (w *Watcher) Reload() error {
+	w.reloadMu.Lock()
+	defer w.reloadMu.Unlock()
+
+	next, err := ReadConfig(w.path)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	prev := w.current
+	w.current = next
+	handlers := w.onChange
+	w.mu.Unlock()
+
+	if reflect.DeepEqual(prev, next) {
+		return nil
+	}
+
+	for _, handler := range handlers {
+		handler(prev, next)
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatcherReloadsUntilStopped(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig := func(level string) {
+		t.Helper()
+
+		err := os.WriteFile(path, []byte(requiredConfig+"logging:\n  level: "+level+"\n"), 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writeConfig("info")
+	watcher, err := NewWatcher(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes := make(chan Config, 10)
+	watcher.OnChange(func(_, next Config) { changes <- next })
+
+	err = watcher.Watch()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeConfig("debug")
+	select {
+	case next := <-changes:
+		if next.Logging.Level != "debug" {
+			t.Errorf("reloaded level = %q, want debug", next.Logging.Level)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("configuration was not reloaded after the file changed")
+	}
+
+	err = watcher.Stop()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeConfig("warn")
+	select {
+	case next := <-changes:
+		t.Errorf("configuration reloaded to level %q after Stop", next.Logging.Level)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	if level := watcher.Config().Logging.Level; level != "debug" {
+		t.Errorf("level after Stop = %q, want debug", level)
+	}
+}
+
+func TestWatcherStopWithoutWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(path, []byte(requiredConfig), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	watcher, err := NewWatcher(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = watcher.Stop()
+	if err != nil {
+		t.Errorf("Stop() = %v, want nil", err)
+	}
+}
+
+// TestWatcherFollowsConfigMapUpdate swaps the ..data symlink the way the kubelet updates a mounted
+// config map: no event names config.yaml itself.
+func TestWatcherFollowsConfigMapUpdate(t *testing.T) {
+	dir := t.TempDir()
+	writeVersion := func(version, level string) {
+		t.Helper()
+
+		err := os.Mkdir(filepath.Join(dir, version), 0o700)
+		if err == nil {
+			err = os.WriteFile(filepath.Join(dir, version, "config.yaml"), []byte(requiredConfig+"logging:\n  level: "+level+"\n"), 0o600)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writeVersion("..2026_10_19_1", "info")
+	for _, link := range [][2]string{{"..2026_10_19_1", "..data"}, {"..data/config.yaml", "config.yaml"}} {
+		if err := os.Symlink(link[0], filepath.Join(dir, link[1])); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	watcher, err := NewWatcher(filepath.Join(dir, "config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = watcher.Stop() })
+
+	changes := make(chan Config, 10)
+	watcher.OnChange(func(_, next Config) { changes <- next })
+
+	err = watcher.Watch()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeVersion("..2026_10_19_2", "debug")
+	err = os.Symlink("..2026_10_19_2", filepath.Join(dir, "..data_tmp"))
+	if err == nil {
+		err = os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data"))
+	}
+	if err == nil {
+		err = os.RemoveAll(filepath.Join(dir, "..2026_10_19_1"))
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case next := <-changes:
+		if next.Logging.Level != "debug" {
+			t.Errorf("reloaded level = %q, want debug", next.Logging.Level)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("configuration was not reloaded after the ..data symlink was swapped")
+	}
+}
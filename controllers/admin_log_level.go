@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/cyber/test-project/logging"
+	"github.com/cyber/test-project/models"
+	"github.com/cyber/test-project/transport"
+	"github.com/cyber/test-project/validation"
+)
+
+type LogLevels struct {
+	Level      string            `json:"level" validate:"oneof=debug info warn error dpanic panic fatal"`
+	Components map[string]string `json:"components"`
+}
+
+func AdminGetLogLevel() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		transport.SendJson(r.Context(), w, http.StatusOK, currentLogLevels())
+	}
+}
+
+// AdminSetLogLevel changes the global level and/or component overrides. An empty component level
+// removes the override, so that the component follows the global level again.
+func AdminSetLogLevel() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		var request LogLevels
+		err := validation.BindJSON(w, r, &request)
+		if err != nil {
+			transport.SendError(ctx, w, err)
+			return
+		}
+
+		// Every level is checked before any is applied, so that a rejected request changes nothing.
+		var fields []models.FieldError
+		for _, component := range slices.Sorted(maps.Keys(request.Components)) {
+			level := request.Components[component]
+			if _, err := zapcore.ParseLevel(level); level != "" && err != nil {
+				fields = append(fields, models.FieldError{Field: "components." + component, Message: fmt.Sprintf("unknown level %q", level)})
+			}
+		}
+		if len(fields) > 0 {
+			transport.SendError(ctx, w, models.ErrValidation{Fields: fields})
+			return
+		}
+
+		if request.Level != "" {
+			_ = logging.SetLevel(request.Level)
+		}
+
+		for component, level := range request.Components {
+			if level == "" {
+				logging.ResetComponentLevel(component)
+			} else {
+				_ = logging.SetComponentLevel(component, level)
+			}
+		}
+
+		transport.SendJson(ctx, w, http.StatusOK, currentLogLevels())
+	}
+}
+
+func currentLogLevels() LogLevels {
+	levels := LogLevels{
+		Level:      logging.Level().String(),
+		Components: map[string]string{},
+	}
+
+	for component, level := range logging.ComponentLevels() {
+		levels.Components[component] = level.String()
+	}
+
+	return levels
+}
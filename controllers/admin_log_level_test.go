@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/cyber/test-project/logging"
+	"github.com/cyber/test-project/validation"
+)
+
+func TestAdminSetLogLevel(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantLevel  zapcore.Level
+		wantLevels map[string]zapcore.Level
+	}{
+		{
+			name:       "applies global and component levels",
+			body:       `{"level": "debug", "components": {"clients": "warn", "services": ""}}`,
+			wantStatus: http.StatusOK,
+			wantLevel:  zapcore.DebugLevel,
+			wantLevels: map[string]zapcore.Level{"clients": zapcore.WarnLevel},
+		},
+		{
+			name:       "invalid component changes nothing",
+			body:       `{"level": "debug", "components": {"clients": "warn", "services": "loud"}}`,
+			wantStatus: http.StatusBadRequest,
+			wantLevel:  zapcore.InfoLevel,
+			wantLevels: map[string]zapcore.Level{"services": zapcore.ErrorLevel},
+		},
+		{
+			name:       "body too large",
+			body:       `{"level": "debug", "components": {"` + strings.Repeat("x", validation.MaxBodyBytes) + `": "warn"}}`,
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantLevel:  zapcore.InfoLevel,
+			wantLevels: map[string]zapcore.Level{"services": zapcore.ErrorLevel},
+		},
+		{
+			name:       "invalid global level",
+			body:       `{"level": "loud"}`,
+			wantStatus: http.StatusBadRequest,
+			wantLevel:  zapcore.InfoLevel,
+			wantLevels: map[string]zapcore.Level{"services": zapcore.ErrorLevel},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_ = logging.SetLevel("info")
+			_ = logging.SetComponentLevels(map[string]string{"services": "error"})
+			t.Cleanup(func() {
+				_ = logging.SetLevel("info")
+				_ = logging.SetComponentLevels(nil)
+			})
+
+			recorder := httptest.NewRecorder()
+			AdminSetLogLevel()(recorder, httptest.NewRequest(http.MethodPut, "/admin/log-level", strings.NewReader(tt.body)))
+
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", recorder.Code, tt.wantStatus, recorder.Body)
+			}
+
+			if level := logging.Level(); level != tt.wantLevel {
+				t.Errorf("level = %s, want %s", level, tt.wantLevel)
+			}
+
+			levels := logging.ComponentLevels()
+			if len(levels) != len(tt.wantLevels) {
+				t.Errorf("component levels = %v, want %v", levels, tt.wantLevels)
+			}
+			for component, want := range tt.wantLevels {
+				if levels[component] != want {
+					t.Errorf("%s level = %s, want %s", component, levels[component], want)
+				}
+			}
+		})
+	}
+}
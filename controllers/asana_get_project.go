@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/cyber/test-project/clients"
+	"github.com/cyber/test-project/services"
+	"github.com/cyber/test-project/transport"
+	"github.com/cyber/test-project/validation"
+)
+
+func AsanaGetProject(service services.AsanaProjectGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		var path GidPath
+		err := validation.BindPath(mux.Vars(r), &path)
+		if err != nil {
+			transport.SendError(ctx, w, err)
+			return
+		}
+
+		project, err := service.GetProject(ctx, clients.GetProjectRequest{Gid: path.Gid})
+		if err != nil {
+			transport.SendError(ctx, w, err)
+			return
+		}
+
+		transport.SendJson(ctx, w, http.StatusOK, project)
+	}
+}
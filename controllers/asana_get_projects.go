@@ -2,41 +2,46 @@ package controllers
 
 import (
 	"net/http"
-	"strconv"
 
 	"github.com/cyber/test-project/clients"
 	"github.com/cyber/test-project/services"
 	"github.com/cyber/test-project/transport"
+	"github.com/cyber/test-project/validation"
 )
 
+// GetProjectsQuery is the query of the projects endpoint.
+type GetProjectsQuery struct {
+	Workspace string `query:"workspace" validate:"gid,excludes=team"`
+	Team      string `query:"team" validate:"gid"`
+	Limit     int    `query:"limit" default:"50" validate:"min=1,max=100"`
+	Offset    string `query:"offset"`
+	Archived  *bool  `query:"archived"`
+}
+
+func (q GetProjectsQuery) Request() clients.GetProjectsRequest {
+	return clients.GetProjectsRequest{
+		Workspace: q.Workspace,
+		Team:      q.Team,
+		Limit:     q.Limit,
+		Offset:    q.Offset,
+		Archived:  q.Archived,
+	}
+}
+
 func AsanaGetProjects(service services.AsanaProjectsGetter) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
-		limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+		var query GetProjectsQuery
+		err := validation.BindQuery(r.URL.Query(), &query)
 		if err != nil {
-			limit = 50
-		}
-
-		var archived *bool
-		if r.URL.Query().Has("archived") {
-			archivedVal, err := strconv.ParseBool(r.URL.Query().Get("archived"))
-			if err == nil {
-				archived = &archivedVal
-			}
-		}
-
-		req := clients.GetProjectsRequest{
-			Workspace: r.URL.Query().Get("workspace"),
-			Team:      r.URL.Query().Get("team"),
-			Limit:     limit,
-			Offset:    r.URL.Query().Get("offset"),
-			Archived:  archived,
+			transport.SendError(ctx, w, err)
+			return
 		}
 
-		projects, err := service.GetProjects(ctx, req)
+		projects, err := service.GetProjects(ctx, query.Request())
 		if err != nil {
-			transport.SendJson(ctx, w, http.StatusInternalServerError, err)
+			transport.SendError(ctx, w, err)
 			return
 		}
 
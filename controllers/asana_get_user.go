@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/cyber/test-project/clients"
+	"github.com/cyber/test-project/services"
+	"github.com/cyber/test-project/transport"
+	"github.com/cyber/test-project/validation"
+)
+
+// GidPath is the path of the endpoints of a single resource.
+type GidPath struct {
+	Gid string `path:"gid" validate:"required,gid"`
+}
+
+// UserPath is the path of the user endpoint, which like Asana takes a gid, me or an email address.
+type UserPath struct {
+	Gid string `path:"gid" validate:"required,user"`
+}
+
+func AsanaGetUser(service services.AsanaUserGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		var path UserPath
+		err := validation.BindPath(mux.Vars(r), &path)
+		if err != nil {
+			transport.SendError(ctx, w, err)
+			return
+		}
+
+		user, err := service.GetUser(ctx, clients.GetUserRequest{Gid: path.Gid})
+		if err != nil {
+			transport.SendError(ctx, w, err)
+			return
+		}
+
+		transport.SendJson(ctx, w, http.StatusOK, user)
+	}
+}
@@ -2,32 +2,44 @@ package controllers
 
 import (
 	"net/http"
-	"strconv"
 
 	"github.com/cyber/test-project/clients"
 	"github.com/cyber/test-project/services"
 	"github.com/cyber/test-project/transport"
+	"github.com/cyber/test-project/validation"
 )
 
+// GetUsersQuery is the query of the users endpoint.
+type GetUsersQuery struct {
+	Workspace string `query:"workspace" validate:"gid,excludes=team"`
+	Team      string `query:"team" validate:"gid"`
+	Limit     int    `query:"limit" default:"50" validate:"min=1,max=100"`
+	Offset    string `query:"offset"`
+}
+
+func (q GetUsersQuery) Request() clients.GetUsersRequest {
+	return clients.GetUsersRequest{
+		Workspace: q.Workspace,
+		Team:      q.Team,
+		Limit:     q.Limit,
+		Offset:    q.Offset,
+	}
+}
+
 func AsanaGetUsers(service services.AsanaUsersGetter) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
-		limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+		var query GetUsersQuery
+		err := validation.BindQuery(r.URL.Query(), &query)
 		if err != nil {
-			limit = 50
-		}
-
-		req := clients.GetUsersRequest{
-			Workspace: r.URL.Query().Get("workspace"),
-			Team:      r.URL.Query().Get("team"),
-			Limit:     limit,
-			Offset:    r.URL.Query().Get("offset"),
+			transport.SendError(ctx, w, err)
+			return
 		}
 
-		users, err := service.GetUsers(ctx, req)
+		users, err := service.GetUsers(ctx, query.Request())
 		if err != nil {
-			transport.SendJson(ctx, w, http.StatusInternalServerError, err)
+			transport.SendError(ctx, w, err)
 			return
 		}
 
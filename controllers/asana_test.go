@@ -0,0 +1,170 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/cyber/test-project/asanatest"
+	"github.com/cyber/test-project/clients"
+	"github.com/cyber/test-project/config"
+	"github.com/cyber/test-project/services"
+)
+
+// newTestAsanaRouter serves the Asana controllers backed by a service calling server.
+func newTestAsanaRouter(t *testing.T, server *asanatest.Server, token string) *mux.Router {
+	t.Helper()
+
+	client := clients.NewAsanaClient(clients.ClientOptions{ServiceName: "asana", BaseURL: server.URL})
+	dumper := services.NewAsanaDataDumper(config.DataDumperConfig{Path: t.TempDir()})
+	service := services.NewAsanaService(client, config.AsanaConfig{AccessToken: token}, dumper)
+
+	router := mux.NewRouter()
+	router.Handle("/users", AsanaGetUsers(service))
+	router.Handle("/users/{gid}", AsanaGetUser(service))
+	router.Handle("/projects", AsanaGetProjects(service))
+	router.Handle("/projects/{gid}", AsanaGetProject(service))
+
+	return router
+}
+
+func TestAsanaControllers(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		token      string
+		faultPath  string
+		fault      asanatest.Fault
+		wantStatus int
+		wantCount  int
+		wantNext   string
+	}{
+		{
+			name:       "first users page",
+			target:     "/users?workspace=1000&limit=2",
+			wantStatus: http.StatusOK,
+			wantCount:  2,
+			wantNext:   "2",
+		},
+		{
+			name:       "next users page",
+			target:     "/users?workspace=1000&limit=2&offset=4",
+			wantStatus: http.StatusOK,
+			wantCount:  1,
+		},
+		{
+			name:       "projects",
+			target:     "/projects?workspace=2000",
+			wantStatus: http.StatusOK,
+			wantCount:  2,
+		},
+		{
+			name:       "project",
+			target:     "/projects/3301",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "user by gid",
+			target:     "/users/1202",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "user me",
+			target:     "/users/me",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "user by email",
+			target:     "/users/ada@example.com",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid user",
+			target:     "/users/ada",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid project gid",
+			target:     "/projects/me",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid query",
+			target:     "/users?limit=500",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "upstream not found is not found",
+			target:     "/users/404",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "upstream unauthorized is an internal error",
+			target:     "/users/1201",
+			token:      "revoked",
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:       "upstream rate limit is an internal error",
+			target:     "/projects",
+			faultPath:  asanatest.ProjectsPath,
+			fault:      asanatest.RateLimited(time.Second),
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:       "upstream server error is an internal error",
+			target:     "/projects/3301",
+			faultPath:  asanatest.ProjectsPath + "/3301",
+			fault:      asanatest.ServerError(http.StatusServiceUnavailable),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := asanatest.NewServer(asanatest.DefaultFixtures())
+			defer server.Close()
+
+			if tt.faultPath != "" {
+				server.InjectFault(tt.faultPath, tt.fault)
+			}
+
+			token := tt.token
+			if token == "" {
+				token = asanatest.DefaultToken
+			}
+
+			recorder := httptest.NewRecorder()
+			newTestAsanaRouter(t, server, token).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, tt.wantStatus, recorder.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var body struct {
+				Data     json.RawMessage `json:"data"`
+				NextPage struct {
+					Offset string `json:"offset"`
+				} `json:"next_page"`
+			}
+			if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+
+			var list []json.RawMessage
+			if json.Unmarshal(body.Data, &list) == nil && len(list) != tt.wantCount {
+				t.Errorf("count = %d, want %d", len(list), tt.wantCount)
+			}
+			if body.NextPage.Offset != tt.wantNext {
+				t.Errorf("next_page.offset = %q, want %q", body.NextPage.Offset, tt.wantNext)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/cyber/test-project/health"
+	"github.com/cyber/test-project/transport"
+)
+
+type ReadinessChecker interface {
+	Check(ctx context.Context) health.Report
+}
+
+func HealthLive() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		transport.SendJson(r.Context(), w, http.StatusOK, health.Report{Status: health.StatusOK})
+	}
+}
+
+func HealthReady(checker ReadinessChecker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		report := checker.Check(ctx)
+		if !report.Healthy() {
+			transport.SendJson(ctx, w, http.StatusServiceUnavailable, report)
+			return
+		}
+
+		transport.SendJson(ctx, w, http.StatusOK, report)
+	}
+}
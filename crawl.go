@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cyber/test-project/app"
+	"github.com/cyber/test-project/services"
+	"github.com/cyber/test-project/shutdown"
+)
+
+type crawlFlags struct {
+	configPath string
+	workspace  string
+	types      string
+	pageSize   int
+	interval   time.Duration
+	out        string
+	format     string
+}
+
+func newCrawlFlags(name string) (*flag.FlagSet, *crawlFlags) {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	cf := &crawlFlags{}
+	flags.StringVar(&cf.configPath, "config", "config.yaml", "Path to configuration file")
+	flags.StringVar(&cf.workspace, "workspace", "", "Asana workspace gid to crawl (required)")
+	flags.StringVar(&cf.types, "types", strings.Join(services.ResourceTypes, ","),
+		"Comma-separated resource types to crawl: "+strings.Join(services.ResourceTypes, ", "))
+	flags.IntVar(&cf.pageSize, "page-size", services.DefaultCrawlPageSize, "Resources requested per page, at most 100")
+
+	return flags, cf
+}
+
+func newDumpFlags() (*flag.FlagSet, *crawlFlags) {
+	return newCrawlFlags("dump")
+}
+
+func newSyncFlags() (*flag.FlagSet, *crawlFlags) {
+	flags, cf := newCrawlFlags("sync")
+	flags.DurationVar(&cf.interval, "interval", 15*time.Minute, "Time between crawls")
+
+	return flags, cf
+}
+
+func newExportFlags() (*flag.FlagSet, *crawlFlags) {
+	flags, cf := newCrawlFlags("export")
+	flags.StringVar(&cf.out, "out", "export", "Directory to write export files to")
+	flags.StringVar(&cf.format, "format", services.ExportFormatNDJSON,
+		"Export file format: "+services.ExportFormatJSON+" or "+services.ExportFormatNDJSON)
+
+	return flags, cf
+}
+
+func (cf *crawlFlags) request() (services.CrawlRequest, error) {
+	if cf.workspace == "" {
+		return services.CrawlRequest{}, errors.New("-workspace is required")
+	}
+
+	if cf.pageSize < 1 || cf.pageSize > 100 {
+		return services.CrawlRequest{}, errors.New("-page-size must be between 1 and 100")
+	}
+
+	types := splitList(cf.types)
+	if len(types) == 0 {
+		return services.CrawlRequest{}, errors.New("-types must name at least one resource type")
+	}
+
+	return services.CrawlRequest{
+		Workspace: cf.workspace,
+		Types:     types,
+		PageSize:  cf.pageSize,
+	}, nil
+}
+
+// withApplication parses the request, initializes the application and runs fn with a context that
+// is cancelled on SIGINT/SIGTERM. Only long-running commands reload the configuration on SIGHUP,
+// one-shot commands keep the configuration they started with.
+func withApplication(flags *flag.FlagSet, cf *crawlFlags, args []string, longRunning bool, fn func(context.Context, *app.Application, services.CrawlRequest) error) int {
+	_ = flags.Parse(args)
+
+	request, err := cf.request()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flags.Usage()
+		return 2
+	}
+
+	application, err := app.InitApplication(cf.configPath)
+	if err != nil {
+		log.Printf("Failed to initialize application: %v", err)
+		return 1
+	}
+
+	var reloaders []shutdown.Reloader
+	if longRunning {
+		reloaders = append(reloaders, application)
+	}
+
+	ctx, cancel := shutdown.ListenForSignals(context.Background(), []os.Signal{os.Interrupt, syscall.SIGTERM}, reloaders...)
+	defer cancel()
+
+	err = fn(ctx, application, request)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s failed: %v\n", flags.Name(), err)
+		return 1
+	}
+
+	return 0
+}
+
+func runDump(args []string) int {
+	flags, cf := newDumpFlags()
+
+	return withApplication(flags, cf, args, false, func(ctx context.Context, application *app.Application, request services.CrawlRequest) error {
+		summary, err := application.Dump(ctx, request)
+		printSummary(os.Stdout, summary)
+
+		return err
+	})
+}
+
+func runSync(args []string) int {
+	flags, cf := newSyncFlags()
+
+	return withApplication(flags, cf, args, true, func(ctx context.Context, application *app.Application, request services.CrawlRequest) error {
+		if cf.interval <= 0 {
+			return errors.New("-interval must be positive")
+		}
+
+		return application.Sync(ctx, request, cf.interval, func(summary services.CrawlSummary, err error) {
+			fmt.Printf("sync at %s\n", time.Now().Format(time.RFC3339))
+			printSummary(os.Stdout, summary)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "sync failed: %v\n", err)
+			}
+		})
+	})
+}
+
+func runExport(args []string) int {
+	flags, cf := newExportFlags()
+
+	return withApplication(flags, cf, args, false, func(ctx context.Context, application *app.Application, request services.CrawlRequest) error {
+		exporter, err := services.NewBulkExporter(cf.out, cf.format)
+		if err != nil {
+			return err
+		}
+
+		summary, err := application.Export(ctx, request, exporter)
+		printSummary(os.Stdout, summary)
+		for _, resourceType := range request.Types {
+			if _, ok := summary.Resources[resourceType]; ok {
+				fmt.Printf("%s written to %s\n", resourceType, exporter.Path(resourceType))
+			}
+		}
+
+		return err
+	})
+}
+
+func printSummary(w io.Writer, summary services.CrawlSummary) {
+	types := make([]string, 0, len(summary.Resources))
+	for resourceType := range summary.Resources {
+		types = append(types, resourceType)
+	}
+	sort.Strings(types)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TYPE\tRESOURCES")
+	total := 0
+	for _, resourceType := range types {
+		fmt.Fprintf(tw, "%s\t%d\n", resourceType, summary.Resources[resourceType])
+		total += summary.Resources[resourceType]
+	}
+	fmt.Fprintf(tw, "total\t%d\n", total)
+	_ = tw.Flush()
+
+	fmt.Fprintf(w, "pages: %d, duration: %s\n", summary.Pages, summary.Duration.Round(time.Millisecond))
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/cyber/test-project/asanatest"
+)
+
+type fakeAsanaFlags struct {
+	addr           string
+	fixtures       string
+	token          string
+	rateLimitEvery int
+	retryAfter     time.Duration
+	errorEvery     int
+}
+
+func newFakeAsanaFlags() (*flag.FlagSet, *fakeAsanaFlags) {
+	flags := flag.NewFlagSet("fake-asana", flag.ExitOnError)
+	ff := &fakeAsanaFlags{}
+	flags.StringVar(&ff.addr, "addr", "127.0.0.1:8002", "Address to listen on")
+	flags.StringVar(&ff.fixtures, "fixtures", "", "Directory with users.json and projects.json; built-in fixtures when empty")
+	flags.StringVar(&ff.token, "token", asanatest.DefaultToken, "Access token accepted by the fake")
+	flags.IntVar(&ff.rateLimitEvery, "rate-limit-every", 0, "Answer every n-th request with 429, disabled when 0")
+	flags.DurationVar(&ff.retryAfter, "retry-after", time.Second, "Retry-After sent with 429 responses")
+	flags.IntVar(&ff.errorEvery, "error-every", 0, "Answer every n-th request with 500, disabled when 0")
+
+	return flags, ff
+}
+
+func runFakeAsana(args []string) int {
+	flags, ff := newFakeAsanaFlags()
+	_ = flags.Parse(args)
+
+	fixtures := asanatest.DefaultFixtures()
+	if ff.fixtures != "" {
+		var err error
+		fixtures, err = asanatest.LoadFixtures(ff.fixtures)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to load fixtures: %v\n", err)
+			return 1
+		}
+	}
+
+	fake := asanatest.NewFake(fixtures,
+		asanatest.WithToken(ff.token),
+		asanatest.WithFaultEvery(ff.rateLimitEvery, asanatest.RateLimited(ff.retryAfter)),
+		asanatest.WithFaultEvery(ff.errorEvery, asanatest.ServerError(http.StatusInternalServerError)),
+	)
+
+	listener, err := net.Listen("tcp", ff.addr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	server := &http.Server{Handler: fake}
+	log.Printf("fake Asana listening on http://%s with token %q (%d users, %d projects)",
+		listener.Addr(), ff.token, len(fixtures.Users), len(fixtures.Projects))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		_ = server.Shutdown(context.Background())
+	}()
+
+	err = server.Serve(listener)
+	if !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/cyber/test-project/auth"
+)
+
+// hashAPIKey prints the hash to put in auth.api_keys for the API key read from stdin, so that the
+// key does not end up in the shell history.
+func hashAPIKey([]string) int {
+	err := printAPIKeyHash(os.Stdin, os.Stdout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	return 0
+}
+
+func printAPIKeyHash(r io.Reader, w io.Writer) error {
+	line, err := bufio.NewReader(r).ReadString('\n')
+	key := strings.TrimSpace(line)
+	if key == "" {
+		if err != nil {
+			return fmt.Errorf("reading the API key from stdin: %w", err)
+		}
+		return errors.New("the API key is empty")
+	}
+
+	_, err = fmt.Fprintln(w, auth.HashAPIKey(key))
+
+	return err
+}
@@ -0,0 +1,157 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+type CheckFunc func(ctx context.Context) error
+
+type CheckResult struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+func (r Report) Healthy() bool {
+	return r.Status == StatusOK
+}
+
+type namedCheck struct {
+	name  string
+	check CheckFunc
+}
+
+// Checker runs all registered checks concurrently, each bounded by the check timeout.
+type Checker struct {
+	timeout time.Duration
+	mu      sync.RWMutex
+	checks  []namedCheck
+}
+
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{
+		timeout: timeout,
+	}
+}
+
+func (c *Checker) Register(name string, check CheckFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+func (c *Checker) Check(ctx context.Context) Report {
+	c.mu.RLock()
+	checks := c.checks
+	c.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	results := make([]CheckResult, len(checks))
+
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = runCheck(ctx, check.check)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{
+		Status: StatusOK,
+		Checks: make(map[string]CheckResult, len(checks)),
+	}
+	for i, check := range checks {
+		report.Checks[check.name] = results[i]
+		if results[i].Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+
+	return report
+}
+
+func runCheck(ctx context.Context, check CheckFunc) CheckResult {
+	started := time.Now()
+	err := check(ctx)
+
+	result := CheckResult{
+		Status:    StatusOK,
+		LatencyMs: float64(time.Since(started).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// Cached wraps a check so that its result is reused for ttl, protecting expensive or rate limited
+// dependencies from being hit by every probe. A single caller refreshes an expired result while the
+// others get the previous one, or wait for the first result when there is none yet.
+func Cached(check CheckFunc, ttl time.Duration) CheckFunc {
+	var (
+		mu        sync.Mutex
+		lastErr   error
+		checkedAt time.Time
+		// refreshed is closed when the running check finishes, nil while none runs.
+		refreshed chan struct{}
+	)
+
+	return func(ctx context.Context) error {
+		mu.Lock()
+
+		if !checkedAt.IsZero() && time.Since(checkedAt) < ttl {
+			defer mu.Unlock()
+			return lastErr
+		}
+
+		if refreshed != nil {
+			done, stale, checked := refreshed, lastErr, !checkedAt.IsZero()
+			mu.Unlock()
+
+			if checked {
+				return stale
+			}
+
+			select {
+			case <-done:
+				mu.Lock()
+				defer mu.Unlock()
+				return lastErr
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		done := make(chan struct{})
+		refreshed = done
+		mu.Unlock()
+
+		err := check(ctx)
+
+		mu.Lock()
+		lastErr, checkedAt, refreshed = err, time.Now(), nil
+		mu.Unlock()
+		close(done)
+
+		return err
+	}
+}
@@ -0,0 +1,102 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// blockingCheck counts its calls and blocks each one until release is closed.
+type blockingCheck struct {
+	calls   atomic.Int32
+	started chan struct{}
+	release chan struct{}
+	err     error
+}
+
+func newBlockingCheck(err error) *blockingCheck {
+	return &blockingCheck{started: make(chan struct{}, 10), release: make(chan struct{}), err: err}
+}
+
+func (c *blockingCheck) check(context.Context) error {
+	c.calls.Add(1)
+	c.started <- struct{}{}
+	<-c.release
+
+	return c.err
+}
+
+func TestCachedReturnsStaleResultDuringRefresh(t *testing.T) {
+	errStale := errors.New("stale")
+	slow := newBlockingCheck(nil)
+
+	first := true
+	cached := Cached(func(ctx context.Context) error {
+		if first {
+			first = false
+			return errStale
+		}
+		return slow.check(ctx)
+	}, time.Millisecond)
+
+	if err := cached(context.Background()); !errors.Is(err, errStale) {
+		t.Fatalf("first check = %v, want %v", err, errStale)
+	}
+	time.Sleep(2 * time.Millisecond)
+
+	refreshDone := make(chan error, 1)
+	go func() { refreshDone <- cached(context.Background()) }()
+	<-slow.started
+
+	// The refresh is still blocked upstream, other probes neither wait nor call it again.
+	for range 3 {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		err := cached(ctx)
+		cancel()
+		if !errors.Is(err, errStale) {
+			t.Errorf("check during refresh = %v, want the stale %v", err, errStale)
+		}
+	}
+
+	close(slow.release)
+	if err := <-refreshDone; err != nil {
+		t.Errorf("refresh = %v, want nil", err)
+	}
+	if err := cached(context.Background()); err != nil {
+		t.Errorf("check after refresh = %v, want nil", err)
+	}
+	if calls := slow.calls.Load(); calls != 1 {
+		t.Errorf("refresh calls = %d, want 1", calls)
+	}
+}
+
+func TestCachedWaitsForFirstResult(t *testing.T) {
+	errDown := errors.New("down")
+	slow := newBlockingCheck(errDown)
+	cached := Cached(slow.check, time.Minute)
+
+	leaderDone := make(chan error, 1)
+	go func() { leaderDone <- cached(context.Background()) }()
+	<-slow.started
+
+	timedOut, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := cached(timedOut); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("check with an expired probe timeout = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	waiterDone := make(chan error, 1)
+	go func() { waiterDone <- cached(context.Background()) }()
+
+	close(slow.release)
+	for _, done := range []chan error{leaderDone, waiterDone} {
+		if err := <-done; !errors.Is(err, errDown) {
+			t.Errorf("check = %v, want %v", err, errDown)
+		}
+	}
+	if calls := slow.calls.Load(); calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
@@ -0,0 +1,131 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+
+	"github.com/cyber/test-project/appcontext"
+)
+
+type Hook struct {
+	Name    string
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+}
+
+// Lifecycle starts hooks in the order they were appended and stops the started ones in reverse order.
+// Components that keep running in the background after OnStart report fatal errors through Fail.
+type Lifecycle struct {
+	mu      sync.Mutex
+	hooks   []Hook
+	started int
+	ready   atomic.Bool
+
+	failMu   sync.Mutex
+	failErr  error
+	failed   chan struct{}
+	failOnce sync.Once
+}
+
+func New() *Lifecycle {
+	return &Lifecycle{
+		failed: make(chan struct{}),
+	}
+}
+
+func (l *Lifecycle) Append(hook Hook) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.hooks = append(l.hooks, hook)
+}
+
+func (l *Lifecycle) Ready() bool {
+	return l.ready.Load()
+}
+
+func (l *Lifecycle) Start(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for l.started < len(l.hooks) {
+		hook := l.hooks[l.started]
+		if hook.OnStart != nil {
+			appcontext.Logger(ctx).Debug("starting component", zap.String("component", hook.Name))
+
+			err := hook.OnStart(ctx)
+			if err != nil {
+				return fmt.Errorf("start %s: %w", hook.Name, err)
+			}
+		}
+		l.started++
+	}
+
+	l.ready.Store(true)
+
+	return nil
+}
+
+// Stop marks the lifecycle as not ready and, if it was ready, waits delay before stopping anything, so
+// that readiness probes fail and traffic moves away while components still serve it. Errors from all
+// hooks are collected.
+func (l *Lifecycle) Stop(ctx context.Context, delay time.Duration) error {
+	if l.ready.Swap(false) && delay > 0 {
+		appcontext.Logger(ctx).Info("waiting before stopping components", zap.Duration("delay", delay))
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var errs error
+	for ; l.started > 0; l.started-- {
+		hook := l.hooks[l.started-1]
+		if hook.OnStop == nil {
+			continue
+		}
+
+		appcontext.Logger(ctx).Debug("stopping component", zap.String("component", hook.Name))
+
+		err := hook.OnStop(ctx)
+		if err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("stop %s: %w", hook.Name, err))
+		}
+	}
+
+	return errs
+}
+
+func (l *Lifecycle) Fail(err error) {
+	l.failMu.Lock()
+	l.failErr = multierr.Append(l.failErr, err)
+	l.failMu.Unlock()
+
+	l.failOnce.Do(func() {
+		close(l.failed)
+	})
+}
+
+// Failed is closed when the first component failure is reported.
+func (l *Lifecycle) Failed() <-chan struct{} {
+	return l.failed
+}
+
+func (l *Lifecycle) Err() error {
+	l.failMu.Lock()
+	defer l.failMu.Unlock()
+
+	return l.failErr
+}
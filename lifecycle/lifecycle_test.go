@@ -0,0 +1,80 @@
+package lifecycle
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestStopWaitsNotReadyBeforeStoppingHooks(t *testing.T) {
+	const delay = 200 * time.Millisecond
+
+	l := New()
+	stopped := make(chan time.Time, 1)
+	l.Append(Hook{
+		Name:   "http_server",
+		OnStop: func(context.Context) error { stopped <- time.Now(); return nil },
+	})
+
+	if err := l.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !l.Ready() {
+		t.Fatal("not ready after Start")
+	}
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- l.Stop(context.Background(), delay) }()
+
+	for l.Ready() {
+		if time.Since(start) > delay/2 {
+			t.Fatal("still ready during the stop delay")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if len(stopped) > 0 {
+		t.Fatal("hook stopped before the delay passed")
+	}
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if waited := (<-stopped).Sub(start); waited < delay {
+		t.Errorf("hook stopped after %s, want at least %s", waited, delay)
+	}
+}
+
+func TestStopSkipsDelay(t *testing.T) {
+	tests := []struct {
+		name  string
+		start bool
+		ctx   func() context.Context
+	}{
+		{name: "never ready", ctx: context.Background},
+		{name: "canceled context", start: true, ctx: func() context.Context {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			return ctx
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New()
+			if tt.start {
+				if err := l.Start(context.Background()); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			start := time.Now()
+			if err := l.Stop(tt.ctx(), time.Minute); err != nil {
+				t.Fatal(err)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("Stop() took %s", elapsed)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package logging
+
+import (
+	"maps"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var componentLevels = struct {
+	sync.RWMutex
+	levels map[string]zapcore.Level
+}{
+	levels: map[string]zapcore.Level{},
+}
+
+// levelCore filters entries by a dynamic level enabler. The wrapped core is built with the lowest
+// level, so that component loggers can be more verbose than the root logger.
+type levelCore struct {
+	zapcore.Core
+	enabler zapcore.LevelEnabler
+}
+
+func (c levelCore) Enabled(level zapcore.Level) bool {
+	return c.enabler.Enabled(level)
+}
+
+func (c levelCore) Level() zapcore.Level {
+	return zapcore.LevelOf(c.enabler)
+}
+
+func (c levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return levelCore{Core: c.Core.With(fields), enabler: c.enabler}
+}
+
+func (c levelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(entry.Level) {
+		return checked
+	}
+
+	return c.Core.Check(entry, checked)
+}
+
+type componentEnabler string
+
+func (c componentEnabler) Enabled(level zapcore.Level) bool {
+	return level >= componentLevel(string(c))
+}
+
+func (c componentEnabler) Level() zapcore.Level {
+	return componentLevel(string(c))
+}
+
+func componentLevel(component string) zapcore.Level {
+	componentLevels.RLock()
+	level, ok := componentLevels.levels[component]
+	componentLevels.RUnlock()
+
+	if !ok {
+		return atomicLevel.Level()
+	}
+
+	return level
+}
+
+// ForComponent returns a logger that keeps all fields of logger but is filtered by the level
+// of the component, which falls back to the global level when it has no override.
+func ForComponent(logger *zap.Logger, component string) *zap.Logger {
+	return logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		if wrapped, ok := core.(levelCore); ok {
+			core = wrapped.Core
+		}
+
+		return levelCore{Core: core, enabler: componentEnabler(component)}
+	}))
+}
+
+func Level() zapcore.Level {
+	return atomicLevel.Level()
+}
+
+func ComponentLevels() map[string]zapcore.Level {
+	componentLevels.RLock()
+	defer componentLevels.RUnlock()
+
+	return maps.Clone(componentLevels.levels)
+}
+
+func SetComponentLevel(component string, text string) error {
+	level, err := zapcore.ParseLevel(text)
+	if err != nil {
+		return err
+	}
+
+	componentLevels.Lock()
+	defer componentLevels.Unlock()
+
+	componentLevels.levels[component] = level
+
+	return nil
+}
+
+func ResetComponentLevel(component string) {
+	componentLevels.Lock()
+	defer componentLevels.Unlock()
+
+	delete(componentLevels.levels, component)
+}
+
+// SetComponentLevels replaces all component overrides at once.
+func SetComponentLevels(levels map[string]string) error {
+	parsed := make(map[string]zapcore.Level, len(levels))
+	for component, text := range levels {
+		level, err := zapcore.ParseLevel(text)
+		if err != nil {
+			return err
+		}
+		parsed[component] = level
+	}
+
+	componentLevels.Lock()
+	defer componentLevels.Unlock()
+
+	componentLevels.levels = parsed
+
+	return nil
+}
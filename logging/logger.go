@@ -1,7 +1,9 @@
 package logging
 
 import (
-	"context"
+	"errors"
+	"io"
+	"os"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -19,6 +21,8 @@ func defaultLogger() *zap.Logger {
 
 var Logger = defaultLogger()
 
+var atomicLevel = zap.NewAtomicLevelAt(zapcore.InfoLevel)
+
 func Init(settings config.LoggingConfig) error {
 	level := zapcore.InfoLevel
 
@@ -27,45 +31,90 @@ func Init(settings config.LoggingConfig) error {
 		Logger.Error("Failed to set log level", zap.Error(err), zap.String("level", settings.Level))
 	}
 
-	atomicLevel := zap.NewAtomicLevelAt(level)
-	zapConfig := zap.NewProductionConfig()
+	atomicLevel.SetLevel(level)
 
-	zapConfig.Level = atomicLevel
-	zapConfig.OutputPaths = settings.Output
-	zapConfig.DisableStacktrace = !settings.LogStackTrace
-	zapConfig.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	err = SetComponentLevels(settings.Levels)
+	if err != nil {
+		return err
+	}
 
-	Logger, err = zapConfig.Build()
+	fieldRedactor, err := NewRedactor(settings.Redaction)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	errorOutput := zapcore.Lock(os.Stderr)
+
+	var (
+		cores  []zapcore.Core
+		opened []io.Closer
+	)
+	for _, output := range settings.AllOutputs() {
+		sink, closer, err := openSink(output, errorOutput)
+		if err != nil {
+			_ = closeOutputs(opened)
+			return err
+		}
+
+		if closer != nil {
+			opened = append(opened, closer)
+		}
+		cores = append(cores, zapcore.NewCore(newEncoder(output.Encoding, fieldRedactor), sink, zapcore.DebugLevel))
+	}
+
+	core := newLevelSampler(zapcore.NewTee(cores...), settings.Sampling)
+
+	options := []zap.Option{
+		zap.AddCaller(),
+		zap.ErrorOutput(errorOutput),
+	}
+	if settings.LogStackTrace {
+		options = append(options, zap.AddStacktrace(zapcore.ErrorLevel))
+	}
+
+	Logger = zap.New(levelCore{Core: core, enabler: atomicLevel}, options...)
+
+	previous := closers
+	closers = opened
+
+	return closeOutputs(previous)
 }
 
-type key int
+var closers []io.Closer
 
-const (
-	loggerKey key = iota
-)
+// Close releases file outputs opened by Init. The logger must not be used for writing afterwards.
+func Close() error {
+	err := closeOutputs(closers)
+	closers = nil
 
-func FromContext(ctx context.Context) *zap.Logger {
-	ctxLogger, ok := ctx.Value(loggerKey).(*zap.Logger)
-	if !ok {
-		return Logger
+	return err
+}
+
+func closeOutputs(outputs []io.Closer) error {
+	var errs []error
+	for _, closer := range outputs {
+		errs = append(errs, closer.Close())
 	}
 
-	return ctxLogger
+	return errors.Join(errs...)
 }
 
-func WithLogger(ctx context.Context, logger *zap.Logger) context.Context {
-	return context.WithValue(ctx, loggerKey, logger)
+func SetLevel(text string) error {
+	level, err := zapcore.ParseLevel(text)
+	if err != nil {
+		return err
+	}
+
+	atomicLevel.SetLevel(level)
+
+	return nil
 }
 
 type fieldGetter func() zapcore.Field
 
-func DebugField(fieldGetter fieldGetter) zapcore.Field {
-	if !Logger.Core().Enabled(zapcore.DebugLevel) {
+// DebugField evaluates fieldGetter only when logger would write debug entries.
+func DebugField(logger *zap.Logger, fieldGetter fieldGetter) zapcore.Field {
+	if !logger.Core().Enabled(zapcore.DebugLevel) {
 		return zap.Skip()
 	}
 
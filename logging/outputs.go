@@ -0,0 +1,116 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"github.com/cyber/test-project/config"
+)
+
+func newEncoder(encoding string, fieldRedactor *Redactor) zapcore.Encoder {
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+
+	var encoder zapcore.Encoder
+	if encoding == config.LogEncodingConsole {
+		encoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	} else {
+		encoder = zapcore.NewJSONEncoder(encoderConfig)
+	}
+
+	return redactingEncoder{Encoder: encoder, redactor: fieldRedactor}
+}
+
+// openSink opens the sink of output. Errors that happen later, e.g. when a file fails to rotate, are
+// written to errorOutput.
+func openSink(output config.LogOutputConfig, errorOutput zapcore.WriteSyncer) (zapcore.WriteSyncer, io.Closer, error) {
+	switch output.Path {
+	case "stdout":
+		return zapcore.Lock(os.Stdout), nil, nil
+	case "stderr":
+		return zapcore.Lock(os.Stderr), nil, nil
+	}
+
+	if !output.Rotation.Enabled() {
+		sink, closeSink, err := zap.Open(output.Path)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return sink, closerFunc(closeSink), nil
+	}
+
+	rotator := newRotatingFile(output.Path, output.Rotation, errorOutput)
+
+	return zapcore.AddSync(rotator), rotator, nil
+}
+
+type closerFunc func()
+
+func (f closerFunc) Close() error {
+	f()
+	return nil
+}
+
+// rotatingFile rotates by size through lumberjack and, when an interval is configured, also on a timer.
+type rotatingFile struct {
+	*lumberjack.Logger
+	errorOutput zapcore.WriteSyncer
+	stop        chan struct{}
+	stopOnce    sync.Once
+}
+
+func newRotatingFile(path string, cfg config.LogRotationConfig, errorOutput zapcore.WriteSyncer) *rotatingFile {
+	file := &rotatingFile{
+		Logger: &lumberjack.Logger{
+			Filename:   path,
+			MaxSize:    cfg.MaxSizeMB,
+			MaxAge:     int((cfg.MaxAge + 24*time.Hour - 1) / (24 * time.Hour)),
+			MaxBackups: cfg.MaxBackups,
+			LocalTime:  true,
+			Compress:   cfg.Compress,
+		},
+		errorOutput: errorOutput,
+		stop:        make(chan struct{}),
+	}
+
+	if cfg.Interval > 0 {
+		go file.rotateEvery(cfg.Interval)
+	}
+
+	return file
+}
+
+func (f *rotatingFile) rotateEvery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-f.stop:
+			return
+		case <-ticker.C:
+			err := f.Rotate()
+			if err != nil {
+				_, _ = fmt.Fprintf(f.errorOutput, "%s failed to rotate log file %s: %v\n", time.Now().UTC(), f.Filename, err)
+				_ = f.errorOutput.Sync()
+			}
+		}
+	}
+}
+
+func (f *rotatingFile) Close() error {
+	f.stopOnce.Do(func() {
+		close(f.stop)
+	})
+
+	return f.Logger.Close()
+}
@@ -0,0 +1,58 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cyber/test-project/config"
+)
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf strings.Builder
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) Sync() error {
+	return nil
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+func TestRotatingFileReportsErrorsToErrorOutput(t *testing.T) {
+	// A regular file in place of the log directory makes every rotation fail.
+	parent := filepath.Join(t.TempDir(), "not-a-dir")
+	if err := os.WriteFile(parent, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var errorOutput syncBuffer
+	file := newRotatingFile(filepath.Join(parent, "app.log"), config.LogRotationConfig{
+		MaxSizeMB: 1,
+		Interval:  time.Millisecond,
+	}, &errorOutput)
+	defer file.Close()
+
+	deadline := time.Now().Add(time.Second)
+	for !strings.Contains(errorOutput.String(), "failed to rotate log file") {
+		if time.Now().After(deadline) {
+			t.Fatalf("error output = %q, want a rotation error", errorOutput.String())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,279 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/cyber/test-project/config"
+)
+
+const Redacted = "[REDACTED]"
+
+// Byte string fields whose key ends with dumpKeySuffix, e.g. request_dump, are HTTP dumps produced by
+// httputil.DumpRequest or httputil.DumpResponse.
+const dumpKeySuffix = "_dump"
+
+// Redactor masks sensitive values: configured HTTP headers, query params and JSON keys in HTTP dumps,
+// log fields whose key matches a configured JSON key, and anything matching the configured patterns.
+type Redactor struct {
+	headers     map[string]bool
+	queryParams map[string]bool
+	jsonKeys    map[string]bool
+	patterns    []*regexp.Regexp
+}
+
+func NewRedactor(cfg config.RedactionConfig) (*Redactor, error) {
+	r := &Redactor{
+		headers:     make(map[string]bool, len(cfg.Headers)),
+		queryParams: make(map[string]bool, len(cfg.QueryParams)),
+		jsonKeys:    make(map[string]bool, len(cfg.JSONKeys)),
+	}
+
+	for _, header := range cfg.Headers {
+		r.headers[http.CanonicalHeaderKey(header)] = true
+	}
+
+	for _, param := range cfg.QueryParams {
+		r.queryParams[param] = true
+	}
+
+	for _, key := range cfg.JSONKeys {
+		r.jsonKeys[strings.ToLower(key)] = true
+	}
+
+	for _, pattern := range cfg.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		r.patterns = append(r.patterns, re)
+	}
+
+	return r, nil
+}
+
+func (r *Redactor) RedactString(s string) string {
+	for _, re := range r.patterns {
+		s = re.ReplaceAllString(s, Redacted)
+	}
+
+	return s
+}
+
+// RedactField masks the whole value when the field key is sensitive, and pattern matches otherwise.
+func (r *Redactor) RedactField(key string, value string) string {
+	if r.jsonKeys[strings.ToLower(key)] {
+		return Redacted
+	}
+
+	return r.RedactString(value)
+}
+
+// redactBytes masks a byte string field, parsing it as an HTTP dump only when its key says it is one.
+func (r *Redactor) redactBytes(key string, value []byte) []byte {
+	if strings.HasSuffix(key, dumpKeySuffix) {
+		return r.RedactDump(value)
+	}
+
+	if r.jsonKeys[strings.ToLower(key)] {
+		return []byte(Redacted)
+	}
+
+	return []byte(r.RedactString(string(r.RedactJSON(value))))
+}
+
+// redactEncoded encodes a reflected, object or array value the way add would add it to an encoder,
+// and returns it as JSON with sensitive keys and patterns masked. A value that cannot be encoded as
+// JSON is masked as a whole.
+func (r *Redactor) redactEncoded(key string, add func(zapcore.ObjectEncoder)) any {
+	if r.jsonKeys[strings.ToLower(key)] {
+		return Redacted
+	}
+
+	encoder := zapcore.NewMapObjectEncoder()
+	add(encoder)
+
+	encoded, err := json.Marshal(encoder.Fields[key])
+	if err != nil {
+		return Redacted
+	}
+
+	return json.RawMessage(r.RedactString(string(r.RedactJSON(encoded))))
+}
+
+// RedactDump masks a dump produced by httputil.DumpRequest or httputil.DumpResponse.
+func (r *Redactor) RedactDump(dump []byte) []byte {
+	head, body, hasBody := bytes.Cut(dump, []byte("\r\n\r\n"))
+
+	lines := strings.Split(string(head), "\r\n")
+	for i, line := range lines {
+		if i == 0 {
+			lines[i] = r.redactRequestLine(line)
+			continue
+		}
+
+		name, _, found := strings.Cut(line, ":")
+		if found && r.headers[http.CanonicalHeaderKey(strings.TrimSpace(name))] {
+			lines[i] = name + ": " + Redacted
+		}
+	}
+
+	redacted := strings.Join(lines, "\r\n")
+	if hasBody {
+		redacted += "\r\n\r\n" + string(r.RedactJSON(body))
+	}
+
+	return []byte(r.RedactString(redacted))
+}
+
+func (r *Redactor) redactRequestLine(line string) string {
+	parts := strings.SplitN(line, " ", 3)
+	if len(parts) != 3 || !strings.Contains(parts[1], "?") {
+		return line
+	}
+
+	parts[1] = r.RedactURL(parts[1])
+
+	return strings.Join(parts, " ")
+}
+
+// RedactHeader returns a copy of header with the values of sensitive headers masked.
+func (r *Redactor) RedactHeader(header http.Header) http.Header {
+	redacted := header.Clone()
+	for name := range redacted {
+		if r.headers[http.CanonicalHeaderKey(name)] {
+			redacted[name] = []string{Redacted}
+		}
+	}
+
+	return redacted
+}
+
+func (r *Redactor) RedactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.RawQuery == "" {
+		return rawURL
+	}
+
+	query := u.Query()
+	for param := range query {
+		if r.queryParams[param] {
+			query.Set(param, Redacted)
+		}
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
+
+// RedactJSON masks values of sensitive keys at any depth. Non-JSON input is returned as is.
+func (r *Redactor) RedactJSON(body []byte) []byte {
+	var decoded any
+	if len(r.jsonKeys) == 0 || json.Unmarshal(body, &decoded) != nil {
+		return body
+	}
+
+	encoded, err := json.Marshal(r.redactValue(decoded))
+	if err != nil {
+		return body
+	}
+
+	return encoded
+}
+
+func (r *Redactor) redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, nested := range v {
+			if r.jsonKeys[strings.ToLower(key)] {
+				v[key] = Redacted
+				continue
+			}
+			v[key] = r.redactValue(nested)
+		}
+	case []any:
+		for i, nested := range v {
+			v[i] = r.redactValue(nested)
+		}
+	}
+
+	return value
+}
+
+// redactingEncoder applies the redactor to string, byte string, error, reflected, object and array
+// fields, both those added with Logger.With and those passed with each entry.
+type redactingEncoder struct {
+	zapcore.Encoder
+	redactor *Redactor
+}
+
+func (e redactingEncoder) Clone() zapcore.Encoder {
+	return redactingEncoder{Encoder: e.Encoder.Clone(), redactor: e.redactor}
+}
+
+func (e redactingEncoder) AddString(key, value string) {
+	e.Encoder.AddString(key, e.redactor.RedactField(key, value))
+}
+
+func (e redactingEncoder) AddByteString(key string, value []byte) {
+	e.Encoder.AddByteString(key, e.redactor.redactBytes(key, value))
+}
+
+func (e redactingEncoder) AddReflected(key string, value any) error {
+	return e.Encoder.AddReflected(key, e.redactor.redactEncoded(key, func(enc zapcore.ObjectEncoder) {
+		_ = enc.AddReflected(key, value)
+	}))
+}
+
+func (e redactingEncoder) AddObject(key string, value zapcore.ObjectMarshaler) error {
+	return e.Encoder.AddReflected(key, e.redactor.redactEncoded(key, func(enc zapcore.ObjectEncoder) {
+		_ = enc.AddObject(key, value)
+	}))
+}
+
+func (e redactingEncoder) AddArray(key string, value zapcore.ArrayMarshaler) error {
+	return e.Encoder.AddReflected(key, e.redactor.redactEncoded(key, func(enc zapcore.ObjectEncoder) {
+		_ = enc.AddArray(key, value)
+	}))
+}
+
+func (e redactingEncoder) EncodeEntry(entry zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	entry.Message = e.redactor.RedactString(entry.Message)
+
+	redacted := make([]zapcore.Field, len(fields))
+	for i, field := range fields {
+		redacted[i] = e.redactField(field)
+	}
+
+	return e.Encoder.EncodeEntry(entry, redacted)
+}
+
+func (e redactingEncoder) redactField(field zapcore.Field) zapcore.Field {
+	switch field.Type {
+	case zapcore.StringType:
+		field.String = e.redactor.RedactField(field.Key, field.String)
+	case zapcore.ByteStringType:
+		if value, ok := field.Interface.([]byte); ok {
+			field.Interface = e.redactor.redactBytes(field.Key, value)
+		}
+	case zapcore.ReflectType, zapcore.ObjectMarshalerType, zapcore.ArrayMarshalerType:
+		return zap.Reflect(field.Key, e.redactor.redactEncoded(field.Key, field.AddTo))
+	case zapcore.ErrorType:
+		if err, ok := field.Interface.(error); ok {
+			message := err.Error()
+			if redacted := e.redactor.RedactString(message); redacted != message {
+				return zap.String(field.Key, redacted)
+			}
+		}
+	}
+
+	return field
+}
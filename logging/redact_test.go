@@ -0,0 +1,158 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/cyber/test-project/config"
+)
+
+type testUser struct {
+	Gid   string `json:"gid"`
+	Email string `json:"email"`
+}
+
+func (u testUser) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("gid", u.Gid)
+	enc.AddString("email", u.Email)
+	return nil
+}
+
+func newTestRedactingLogger(t *testing.T) (*zap.Logger, *bytes.Buffer) {
+	t.Helper()
+
+	redactor, err := NewRedactor(config.RedactionConfig{
+		Headers:  []string{"Authorization"},
+		JSONKeys: []string{"email"},
+		Patterns: []string{`(?i)bearer\s+[^\s"]+`},
+	})
+	if err != nil {
+		t.Fatalf("NewRedactor() error = %v", err)
+	}
+
+	var out bytes.Buffer
+	core := zapcore.NewCore(newEncoder(config.LogEncodingJSON, redactor), zapcore.AddSync(&out), zapcore.DebugLevel)
+
+	return zap.New(core), &out
+}
+
+func TestRedactingEncoderFields(t *testing.T) {
+	user := testUser{Gid: "1201", Email: "ada@example.com"}
+
+	tests := []struct {
+		name  string
+		log   func(*zap.Logger)
+		field string
+		want  string
+	}{
+		{
+			name:  "any",
+			log:   func(l *zap.Logger) { l.Info("user", zap.Any("user", struct{ Email string }{"ada@example.com"})) },
+			field: "user",
+			want:  `{"Email":"[REDACTED]"}`,
+		},
+		{
+			name:  "reflect",
+			log:   func(l *zap.Logger) { l.Info("users", zap.Reflect("users", []testUser{user})) },
+			field: "users",
+			want:  `[{"email":"[REDACTED]","gid":"1201"}]`,
+		},
+		{
+			name:  "object",
+			log:   func(l *zap.Logger) { l.Info("user", zap.Object("user", user)) },
+			field: "user",
+			want:  `{"email":"[REDACTED]","gid":"1201"}`,
+		},
+		{
+			name:  "reflect with logger",
+			log:   func(l *zap.Logger) { l.With(zap.Reflect("user", user)).Info("user") },
+			field: "user",
+			want:  `{"email":"[REDACTED]","gid":"1201"}`,
+		},
+		{
+			name:  "object with logger",
+			log:   func(l *zap.Logger) { l.With(zap.Object("user", user)).Info("user") },
+			field: "user",
+			want:  `{"email":"[REDACTED]","gid":"1201"}`,
+		},
+		{
+			name:  "sensitive key",
+			log:   func(l *zap.Logger) { l.Info("user", zap.Any("email", []string{"ada@example.com"})) },
+			field: "email",
+			want:  `"[REDACTED]"`,
+		},
+		{
+			name:  "pattern in reflected value",
+			log:   func(l *zap.Logger) { l.Info("header", zap.Any("header", map[string]string{"auth": "Bearer secret"})) },
+			field: "header",
+			want:  `{"auth":"[REDACTED]"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, out := newTestRedactingLogger(t)
+			tt.log(logger)
+
+			var entry map[string]json.RawMessage
+			if err := json.Unmarshal(out.Bytes(), &entry); err != nil {
+				t.Fatalf("decoding %q: %v", out.String(), err)
+			}
+			if got := string(entry[tt.field]); got != tt.want {
+				t.Errorf("%s = %s, want %s", tt.field, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactingEncoderByteStrings(t *testing.T) {
+	dump := "GET /api/1.0/users HTTP/1.1\r\nAuthorization: Bearer secret\r\n\r\n{\"email\":\"ada@example.com\"}"
+
+	tests := []struct {
+		name    string
+		field   zap.Field
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "dump",
+			field:   zap.ByteString("request_dump", []byte(dump)),
+			want:    []string{"Authorization: [REDACTED]", `{\"email\":\"[REDACTED]\"}`},
+			notWant: []string{"secret", "ada@example.com"},
+		},
+		{
+			name:    "json",
+			field:   zap.ByteString("body", []byte(`{"email":"ada@example.com","name":"Ada"}`)),
+			want:    []string{`{\"email\":\"[REDACTED]\",\"name\":\"Ada\"}`},
+			notWant: []string{"ada@example.com"},
+		},
+		{
+			name:  "text with a blank line is not a dump",
+			field: zap.ByteString("note", []byte("note\r\nAuthorization: kept\r\n\r\nbody")),
+			want:  []string{`Authorization: kept`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, out := newTestRedactingLogger(t)
+			logger.Info("bytes", tt.field)
+
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("output %s does not contain %s", out.String(), want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(out.String(), notWant) {
+					t.Errorf("output %s contains %s", out.String(), notWant)
+				}
+			}
+		})
+	}
+}
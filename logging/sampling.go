@@ -0,0 +1,49 @@
+package logging
+
+import (
+	"go.uber.org/zap/zapcore"
+
+	"github.com/cyber/test-project/config"
+)
+
+// levelSampler routes entries to a sampler configured for their level. Levels without a sampler
+// are written unsampled.
+type levelSampler struct {
+	zapcore.Core
+	samplers map[zapcore.Level]zapcore.Core
+}
+
+func newLevelSampler(core zapcore.Core, cfg config.LogSamplingConfig) zapcore.Core {
+	if len(cfg.Levels) == 0 {
+		return core
+	}
+
+	samplers := make(map[zapcore.Level]zapcore.Core, len(cfg.Levels))
+	for text, rate := range cfg.Levels {
+		level, err := zapcore.ParseLevel(text)
+		if err != nil {
+			continue
+		}
+
+		samplers[level] = zapcore.NewSamplerWithOptions(core, cfg.Tick, rate.Initial, rate.Thereafter)
+	}
+
+	return levelSampler{Core: core, samplers: samplers}
+}
+
+func (s levelSampler) With(fields []zapcore.Field) zapcore.Core {
+	samplers := make(map[zapcore.Level]zapcore.Core, len(s.samplers))
+	for level, sampler := range s.samplers {
+		samplers[level] = sampler.With(fields)
+	}
+
+	return levelSampler{Core: s.Core.With(fields), samplers: samplers}
+}
+
+func (s levelSampler) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if sampler, ok := s.samplers[entry.Level]; ok {
+		return sampler.Check(entry, checked)
+	}
+
+	return s.Core.Check(entry, checked)
+}
@@ -2,29 +2,80 @@ package main
 
 import (
 	"flag"
-	"log"
+	"fmt"
+	"io"
 	"os"
-	"syscall"
-
-	"github.com/cyber/test-project/app"
-	"github.com/cyber/test-project/shutdown"
+	"strings"
 )
 
+type command struct {
+	name  string
+	usage string
+	// flags builds the command's flag set without parsing it, for shell completion. Nil when the
+	// command takes no flags.
+	flags func() *flag.FlagSet
+	run   func(args []string) int
+}
+
+var commands = []command{
+	{name: "serve", usage: "run the HTTP service (default)", flags: flagsOnly(newServeFlags), run: serve},
+	{name: "dump", usage: "crawl Asana once into the dump store and print a summary", flags: flagsOnly(newDumpFlags), run: runDump},
+	{name: "sync", usage: "crawl Asana into the dump store periodically", flags: flagsOnly(newSyncFlags), run: runSync},
+	{name: "export", usage: "crawl Asana once into bulk files", flags: flagsOnly(newExportFlags), run: runExport},
+	{name: "query", usage: "inspect resources in the dump store", flags: flagsOnly(newQueryFlags), run: runQuery},
+	{name: "fake-asana", usage: "serve a fake Asana API from fixtures for local development", flags: flagsOnly(newFakeAsanaFlags), run: runFakeAsana},
+	{name: "validate-config", usage: "validate a configuration file and print it with secrets masked", flags: flagsOnly(newValidateConfigFlags), run: validateConfig},
+	{name: "openapi", usage: "print the OpenAPI document of the HTTP API", run: printOpenAPI},
+	{name: "hash-api-key", usage: "hash an API key read from stdin for auth.api_keys", run: hashAPIKey},
+	{name: "version", usage: "print version information", run: printVersion},
+	{name: "completion", usage: "print a bash completion script", run: printCompletion},
+}
+
+func flagsOnly[T any](newFlags func() (*flag.FlagSet, T)) func() *flag.FlagSet {
+	return func() *flag.FlagSet {
+		flags, _ := newFlags()
+		return flags
+	}
+}
+
 func main() {
-	configPath := flag.String("config", "config.yaml", "Path to configuration file")
-	flag.Parse()
+	os.Exit(run(os.Args[1:]))
+}
 
-	application, err := app.InitApplication(*configPath)
-	if err != nil {
-		log.Fatalf("Failed to initialize application: %v", err)
+func run(args []string) int {
+	// Flags without a command keep `test_app -config=...` starting the service.
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return serve(args)
 	}
 
-	go func() {
-		err = application.Start()
-		if err != nil {
-			log.Fatalf("Failed to start application: %v", err)
+	if args[0] == completeCommand {
+		return complete(args[1:])
+	}
+
+	if args[0] == "help" {
+		printUsage(os.Stdout)
+		return 0
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:])
 		}
-	}()
+	}
 
-	shutdown.ListenForSignals([]os.Signal{os.Interrupt, syscall.SIGTERM}, application)
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", args[0])
+	printUsage(os.Stderr)
+
+	return 2
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: test_app <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-16s %s\n", cmd.name, cmd.usage)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run `test_app <command> -h` for command flags.")
 }
@@ -0,0 +1,172 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "test_project"
+
+var Registry = prometheus.NewRegistry()
+
+var (
+	inboundRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http_server",
+		Name:      "requests_total",
+		Help:      "Number of handled inbound HTTP requests.",
+	}, []string{"method", "route", "status"})
+
+	inboundDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http_server",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of inbound HTTP requests.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	outboundRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http_client",
+		Name:      "requests_total",
+		Help:      "Number of outbound HTTP requests to upstream services.",
+	}, []string{"service", "method", "path", "status"})
+
+	outboundDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http_client",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of outbound HTTP requests to upstream services.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"service", "method", "path", "status"})
+
+	rateLimitWait = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http_client",
+		Name:      "rate_limit_wait_seconds",
+		Help:      "Time outbound requests spent waiting for the rate limiter.",
+		Buckets:   []float64{0.001, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+	}, []string{"service"})
+
+	outboundRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http_client",
+		Name:      "retries_total",
+		Help:      "Outbound request attempts that were retried.",
+	}, []string{"service"})
+
+	coalescedRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "asana",
+		Name:      "coalesced_requests_total",
+		Help:      "Asana requests answered by sharing an identical in-flight upstream call.",
+	}, []string{"resource_type"})
+
+	hedgedRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "asana",
+		Name:      "hedged_requests_total",
+		Help:      "Asana requests that sent a hedged second attempt, by the attempt that answered.",
+	}, []string{"resource_type", "winner"})
+
+	dumpedResources = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "data_dumper",
+		Name:      "resources_total",
+		Help:      "Number of resources processed by the data dumper, by result.",
+	}, []string{"resource_type", "result"})
+)
+
+const (
+	DumpWritten = "written"
+	DumpFailed  = "failed"
+)
+
+const (
+	HedgeWinnerPrimary = "primary"
+	HedgeWinnerHedge   = "hedge"
+	HedgeWinnerNone    = "none"
+)
+
+// StatusError is used as the status label for outbound requests that got no HTTP response.
+const StatusError = "error"
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		inboundRequests,
+		inboundDuration,
+		outboundRequests,
+		outboundDuration,
+		rateLimitWait,
+		outboundRetries,
+		coalescedRequests,
+		hedgedRequests,
+		dumpedResources,
+	)
+}
+
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+func ObserveInboundRequest(method, route string, statusCode int, duration time.Duration) {
+	status := strconv.Itoa(statusCode)
+	inboundRequests.WithLabelValues(method, route, status).Inc()
+	inboundDuration.WithLabelValues(method, route, status).Observe(duration.Seconds())
+}
+
+func ObserveOutboundRequest(service, method, path, status string, duration time.Duration) {
+	outboundRequests.WithLabelValues(service, method, path, status).Inc()
+	outboundDuration.WithLabelValues(service, method, path, status).Observe(duration.Seconds())
+}
+
+func ObserveRateLimitWait(service string, duration time.Duration) {
+	rateLimitWait.WithLabelValues(service).Observe(duration.Seconds())
+}
+
+func IncOutboundRetries(service string) {
+	outboundRetries.WithLabelValues(service).Inc()
+}
+
+func IncCoalescedRequests(resourceType string) {
+	coalescedRequests.WithLabelValues(resourceType).Inc()
+}
+
+func IncHedgedRequests(resourceType, winner string) {
+	hedgedRequests.WithLabelValues(resourceType, winner).Inc()
+}
+
+func IncDumpedResources(resourceType, result string) {
+	dumpedResources.WithLabelValues(resourceType, result).Inc()
+}
+
+// RegisterCircuitBreaker exposes the breaker state of a service: 0 - closed, 1 - half-open, 2 - open.
+// Registering a service again, e.g. when the application is initialized twice in one process,
+// replaces the previous breaker.
+func RegisterCircuitBreaker(service string, state func() float64) {
+	gauge := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   namespace,
+		Subsystem:   "circuit_breaker",
+		Name:        "state",
+		Help:        "Circuit breaker state: 0 - closed, 1 - half-open, 2 - open.",
+		ConstLabels: prometheus.Labels{"service": service},
+	}, state)
+
+	err := Registry.Register(gauge)
+	var alreadyRegistered prometheus.AlreadyRegisteredError
+	if errors.As(err, &alreadyRegistered) {
+		Registry.Unregister(alreadyRegistered.ExistingCollector)
+		err = Registry.Register(gauge)
+	}
+	if err != nil {
+		panic(err)
+	}
+}
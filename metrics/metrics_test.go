@@ -0,0 +1,32 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRegisterCircuitBreakerTwice(t *testing.T) {
+	RegisterCircuitBreaker("test", func() float64 { return 2 })
+	RegisterCircuitBreaker("test", func() float64 { return 1 })
+
+	got, err := testutil.GatherAndCount(Registry, "test_project_circuit_breaker_state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 1 {
+		t.Fatalf("%d circuit breaker state series, want 1", got)
+	}
+
+	families, err := Registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() == "test_project_circuit_breaker_state" {
+			if value := family.GetMetric()[0].GetGauge().GetValue(); value != 1 {
+				t.Errorf("state = %v, want the latest breaker's 1", value)
+			}
+		}
+	}
+}
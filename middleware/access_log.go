@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/cyber/test-project/appcontext"
+)
+
+func AccessLog(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started := time.Now()
+		recorder := newResponseRecorder(w)
+
+		h.ServeHTTP(recorder, r)
+
+		appcontext.Logger(r.Context()).Info("access",
+			zap.String("http_method", r.Method),
+			zap.String("route", routeTemplate(r)),
+			zap.String("path", r.URL.Path),
+			zap.Int("status", recorder.status),
+			zap.Int("bytes", recorder.bytes),
+			zap.Duration("duration", time.Since(started)),
+			zap.String("remote_addr", r.RemoteAddr),
+		)
+	})
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/cyber/test-project/appcontext"
+	"github.com/cyber/test-project/auth"
+)
+
+const authRealm = "test-project"
+
+// Authorize lets a request through when its caller authenticates with authenticator and was granted
+// scope, and binds the caller to the request context. Requests without valid credentials get a 401
+// and callers without the scope a 403. A nil authenticator, when authentication is disabled, lets
+// every request through.
+func Authorize(authenticator *auth.Authenticator, scope string, sendError ErrorStatusHandlerFunc) func(h http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		if authenticator == nil {
+			return h
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			logger := appcontext.Logger(ctx)
+
+			principal, err := authenticator.Authenticate(r)
+			if err != nil {
+				logger.Info("request not authenticated", zap.Error(err))
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf("Bearer realm=%q", authRealm))
+				sendError(ctx, w, http.StatusUnauthorized, err)
+				return
+			}
+
+			logger = logger.With(zap.String("subject", principal.Subject), zap.String("auth_method", principal.Method))
+			ctx = appcontext.WithLogger(auth.WithPrincipal(ctx, principal), logger)
+
+			if scope != "" && !principal.HasScope(scope) {
+				logger.Info("request not authorized", zap.String("scope", scope))
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf("Bearer realm=%q, error=\"insufficient_scope\", scope=%q", authRealm, scope))
+				sendError(ctx, w, http.StatusForbidden, fmt.Errorf("the %s scope is required", scope))
+				return
+			}
+
+			h.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// LoopbackOnly lets only requests from a loopback address through, for routes that must not be open
+// to the network while authentication is disabled. Other callers get a 403.
+func LoopbackOnly(sendError ErrorStatusHandlerFunc) func(h http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			host, _, err := net.SplitHostPort(r.RemoteAddr)
+			if ip := net.ParseIP(host); err != nil || ip == nil || !ip.IsLoopback() {
+				appcontext.Logger(r.Context()).Info("request not from loopback", zap.String("remote_addr", r.RemoteAddr))
+				sendError(r.Context(), w, http.StatusForbidden, errors.New("this route only accepts loopback callers while authentication is disabled"))
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
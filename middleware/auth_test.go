@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cyber/test-project/auth"
+	"github.com/cyber/test-project/config"
+)
+
+func sendStatus(_ context.Context, w http.ResponseWriter, statusCode int, err error) {
+	http.Error(w, err.Error(), statusCode)
+}
+
+func TestAuthorize(t *testing.T) {
+	authenticator, err := auth.NewAuthenticator(config.AuthConfig{
+		Enabled: true,
+		APIKeys: []config.APIKeyConfig{
+			{Name: "dashboard", Hash: auth.HashAPIKey("dashboard-key"), Scopes: []string{"users:read"}},
+			{Name: "ops", Hash: auth.HashAPIKey("ops-key"), Scopes: []string{"admin:write"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name             string
+		authenticator    *auth.Authenticator
+		apiKey           string
+		wantStatus       int
+		wantAuthenticate string
+		wantSubject      string
+	}{
+		{
+			name:       "authentication disabled",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:             "no credentials",
+			authenticator:    authenticator,
+			wantStatus:       http.StatusUnauthorized,
+			wantAuthenticate: `Bearer realm="test-project"`,
+		},
+		{
+			name:             "invalid API key",
+			authenticator:    authenticator,
+			apiKey:           "guessed-key",
+			wantStatus:       http.StatusUnauthorized,
+			wantAuthenticate: `Bearer realm="test-project"`,
+		},
+		{
+			name:             "missing scope",
+			authenticator:    authenticator,
+			apiKey:           "ops-key",
+			wantStatus:       http.StatusForbidden,
+			wantAuthenticate: `Bearer realm="test-project", error="insufficient_scope", scope="users:read"`,
+		},
+		{
+			name:          "granted scope",
+			authenticator: authenticator,
+			apiKey:        "dashboard-key",
+			wantStatus:    http.StatusOK,
+			wantSubject:   "dashboard",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var subject string
+			handler := Authorize(tt.authenticator, "users:read", sendStatus)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				principal, _ := auth.FromContext(r.Context())
+				subject = principal.Subject
+			}))
+
+			request := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
+			if tt.apiKey != "" {
+				request.Header.Set(auth.APIKeyHeader, tt.apiKey)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", recorder.Code, tt.wantStatus, recorder.Body)
+			}
+			if got := recorder.Header().Get("WWW-Authenticate"); got != tt.wantAuthenticate {
+				t.Errorf("WWW-Authenticate = %q, want %q", got, tt.wantAuthenticate)
+			}
+			if subject != tt.wantSubject {
+				t.Errorf("principal subject = %q, want %q", subject, tt.wantSubject)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/cyber/test-project/metrics"
+)
+
+func Metrics(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started := time.Now()
+		recorder := newResponseRecorder(w)
+
+		h.ServeHTTP(recorder, r)
+
+		metrics.ObserveInboundRequest(r.Method, routeTemplate(r), recorder.status, time.Since(started))
+	})
+}
+
+func routeTemplate(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return "unmatched"
+	}
+
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return "unknown"
+	}
+
+	return template
+}
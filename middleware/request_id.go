@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/cyber/test-project/appcontext"
+)
+
+const (
+	RequestIDHeader    = "X-Request-ID"
+	maxRequestIDLength = 128
+)
+
+// RequestID accepts the caller's X-Request-ID or generates a new one, echoes it in the response and
+// adds it to the context logger so that all logs written for the request can be correlated.
+func RequestID(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+
+		ctx := appcontext.WithRequestID(r.Context(), requestID)
+		ctx = appcontext.WithLogger(ctx, appcontext.Logger(ctx).With(zap.String("request_id", requestID)))
+
+		w.Header().Set(RequestIDHeader, requestID)
+
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+
+	for _, c := range requestID {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
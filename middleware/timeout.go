@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cyber/test-project/appcontext"
+	"github.com/cyber/test-project/config"
+)
+
+// RequestTimeoutHeader lets callers ask for a tighter deadline than the route's, as a duration
+// (`1.5s`, `500ms`) or a number of milliseconds.
+const RequestTimeoutHeader = "X-Request-Timeout"
+
+type ErrorStatusHandlerFunc = func(ctx context.Context, w http.ResponseWriter, statusCode int, err error)
+
+var errDeadlineExceeded = errors.New("request deadline exceeded")
+
+// Timeout gives every request a time budget from cfg, or from RequestTimeoutHeader when that is
+// tighter. The budget is carried by the request context down to outbound calls. A handler that ran
+// out of budget without responding gets a 504.
+func Timeout(cfg config.RequestTimeoutConfig, sendError ErrorStatusHandlerFunc) func(h http.Handler) http.Handler {
+	routes := make(map[string]time.Duration, len(cfg.Routes))
+	for _, route := range cfg.Routes {
+		routes[route.Path] = route.Timeout
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			budget := cfg.Default
+			if routeBudget, ok := routes[routeTemplate(r)]; ok {
+				budget = routeBudget
+			}
+
+			if header := r.Header.Get(RequestTimeoutHeader); header != "" {
+				requested, err := parseRequestTimeout(header)
+				if err != nil {
+					sendError(r.Context(), w, http.StatusBadRequest, err)
+					return
+				}
+
+				if budget <= 0 || requested < budget {
+					budget = requested
+				}
+			}
+
+			if budget <= 0 {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			ctx, cancel := appcontext.WithBudget(r.Context(), budget)
+			defer cancel()
+
+			recorder := newResponseRecorder(w)
+			h.ServeHTTP(recorder, r.WithContext(ctx))
+
+			if !recorder.written && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				sendError(ctx, w, http.StatusGatewayTimeout, errDeadlineExceeded)
+			}
+		})
+	}
+}
+
+func parseRequestTimeout(header string) (time.Duration, error) {
+	timeout, err := time.ParseDuration(header)
+	if err != nil {
+		ms, atoiErr := strconv.Atoi(header)
+		if atoiErr != nil {
+			return 0, fmt.Errorf("invalid %s header %q", RequestTimeoutHeader, header)
+		}
+		timeout = time.Duration(ms) * time.Millisecond
+	}
+
+	if timeout <= 0 {
+		return 0, fmt.Errorf("%s header must be positive, got %q", RequestTimeoutHeader, header)
+	}
+
+	return timeout, nil
+}
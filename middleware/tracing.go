@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/cyber/test-project/tracing"
+)
+
+func Tracing(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := routeTemplate(r)
+
+		ctx := tracing.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracing.Start(ctx, r.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(r.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(r.URL.Path),
+			),
+		)
+		defer span.End()
+
+		recorder := newResponseRecorder(w)
+		h.ServeHTTP(recorder, r.WithContext(ctx))
+
+		span.SetAttributes(semconv.HTTPResponseStatusCode(recorder.status))
+		if recorder.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(recorder.status))
+		}
+	})
+}
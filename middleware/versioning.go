@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// APIVersionHeader tells which API version served a response.
+const APIVersionHeader = "API-Version"
+
+const versionMediaTypePrefix = "application/vnd.test-project."
+
+// VersionMediaType is the media type that asks for an API version in the Accept header, e.g.
+// application/vnd.test-project.v1+json.
+func VersionMediaType(version string) string {
+	return versionMediaTypePrefix + version + "+json"
+}
+
+// APIVersion marks the responses of a route of the given API version.
+func APIVersion(version string) func(h http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(APIVersionHeader, version)
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// NegotiateVersion serves the handler of the API version asked for with VersionMediaType in the
+// Accept header, or of the latest of versions, which are ordered oldest first, when none is asked
+// for. A request that only accepts unsupported versions gets a 406, and one for a version without
+// a handler a 404.
+func NegotiateVersion(versions []string, handlers map[string]http.Handler, sendError ErrorStatusHandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept")
+
+		version, ok := negotiate(r.Header.Values("Accept"), versions)
+		if !ok {
+			sendError(r.Context(), w, http.StatusNotAcceptable,
+				fmt.Errorf("none of the accepted API versions is supported, supported versions: %s", strings.Join(versions, ", ")))
+			return
+		}
+
+		handler, ok := handlers[version]
+		if !ok {
+			sendError(r.Context(), w, http.StatusNotFound, fmt.Errorf("route is not available in API version %s", version))
+			return
+		}
+
+		APIVersion(version)(handler).ServeHTTP(w, r)
+	})
+}
+
+// negotiate returns the first supported version listed in accept. Without any version media type
+// in accept, or when accept also takes other JSON, the latest version is used.
+func negotiate(accept []string, versions []string) (string, bool) {
+	latest := versions[len(versions)-1]
+
+	askedForVersion, acceptsOther := false, len(accept) == 0
+	for _, header := range accept {
+		for _, mediaRange := range strings.Split(header, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+			if err != nil || params["q"] == "0" {
+				continue
+			}
+
+			version, ok := strings.CutPrefix(mediaType, versionMediaTypePrefix)
+			if !ok {
+				acceptsOther = acceptsOther || mediaType == "application/json" || mediaType == "application/*" || mediaType == "*/*"
+				continue
+			}
+
+			version = strings.TrimSuffix(version, "+json")
+			for _, supported := range versions {
+				if version == supported {
+					return version, true
+				}
+			}
+			askedForVersion = true
+		}
+	}
+
+	if askedForVersion && !acceptsOther {
+		return "", false
+	}
+
+	return latest, true
+}
+
+// Deprecated announces on every response of a route that it is deprecated since deprecatedAt and
+// will be removed at sunset, with the Deprecation (RFC 9745) and Sunset (RFC 8594) headers and a
+// link to its successor.
+func Deprecated(deprecatedAt, sunset time.Time, successor string) func(h http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", fmt.Sprintf("@%d", deprecatedAt.Unix()))
+			w.Header().Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+			w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, successor))
+			h.ServeHTTP(w, r)
+		})
+	}
+}
@@ -15,6 +15,10 @@ type AsanaGetUsersResponse struct {
 	NextPage AsanaNextPage `json:"next_page,omitempty"`
 }
 
+type AsanaGetUserResponse struct {
+	Data AsanaUser `json:"data"`
+}
+
 type TypedResource interface {
 	GetGid() string
 	GetResourceType() string
@@ -71,6 +75,11 @@ type AsanaGetProjectsResponse struct {
 	Data     AsanaProjects `json:"data"`
 	NextPage AsanaNextPage `json:"next_page,omitempty"`
 }
+
+type AsanaGetProjectResponse struct {
+	Data AsanaProjectResource `json:"data"`
+}
+
 type AsanaProjectResource struct {
 	BaseResource
 	Archived      bool               `json:"archived"`
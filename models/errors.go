@@ -1,5 +1,10 @@
 package models
 
+import (
+	"strconv"
+	"strings"
+)
+
 type ErrServiceFailure struct {
 	ServiceName string
 }
@@ -15,3 +20,81 @@ type ErrRateLimitExceeded struct {
 func (e ErrRateLimitExceeded) Error() string {
 	return e.ServiceName + " service rate limit exceeded"
 }
+
+type ErrUnsupportedResourceType struct {
+	ResourceType string
+}
+
+func (e ErrUnsupportedResourceType) Error() string {
+	return "unsupported resource type " + e.ResourceType
+}
+
+type ErrUnsupportedExportFormat struct {
+	Format string
+}
+
+func (e ErrUnsupportedExportFormat) Error() string {
+	return "unsupported export format " + e.Format
+}
+
+type ErrInvalidFilter struct {
+	Expression string
+}
+
+func (e ErrInvalidFilter) Error() string {
+	return "invalid filter " + e.Expression + ", expected <field><op><value> with op one of =, !=, ~"
+}
+
+// ErrServiceResponse is a client error (4xx) returned by an upstream service, with the messages it sent.
+type ErrServiceResponse struct {
+	ServiceName string
+	StatusCode  int
+	Messages    []string
+}
+
+func (e ErrServiceResponse) Error() string {
+	msg := e.ServiceName + " service rejected the request with status " + strconv.Itoa(e.StatusCode)
+	if len(e.Messages) > 0 {
+		msg += ": " + strings.Join(e.Messages, "; ")
+	}
+
+	return msg
+}
+
+// ErrDeadlineExceeded is returned when the request budget ran out before the service answered.
+type ErrDeadlineExceeded struct {
+	ServiceName string
+}
+
+func (e ErrDeadlineExceeded) Error() string {
+	return e.ServiceName + " service did not respond within the request deadline"
+}
+
+// FieldError is one invalid field of a request, named as the client sent it.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ErrRequestTooLarge is returned for request bodies over Limit bytes.
+type ErrRequestTooLarge struct {
+	Limit int64
+}
+
+func (e ErrRequestTooLarge) Error() string {
+	return "request body is larger than " + strconv.FormatInt(e.Limit, 10) + " bytes"
+}
+
+// ErrValidation lists every invalid field of a request.
+type ErrValidation struct {
+	Fields []FieldError
+}
+
+func (e ErrValidation) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.Field+" "+field.Message)
+	}
+
+	return "invalid request: " + strings.Join(messages, "; ")
+}
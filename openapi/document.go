@@ -0,0 +1,297 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/cyber/test-project/validation"
+)
+
+const Version = "3.0.3"
+
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem maps lowercase HTTP methods to the operations of one path.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+	Deprecated  bool                `json:"deprecated,omitempty"`
+	// Security lists alternative requirements, any one of which authorizes the operation.
+	Security []map[string][]string `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// Route documents the route registered on the router under Name.
+type Route struct {
+	Name        string
+	Summary     string
+	Description string
+	Tags        []string
+	// Params is a struct bound with validation.BindPath. When nil, path variables are documented
+	// as plain strings.
+	Params any
+	// Query is a struct bound with validation.BindQuery, nil if the route takes no query.
+	Query any
+	// Response is the body of successful responses.
+	Response   any
+	Deprecated bool
+	// Scope is required from callers authenticated with any of the security schemes, empty if the
+	// route is public.
+	Scope string
+}
+
+var pathParam = regexp.MustCompile(`\{([^}:]+)(?::[^}]*)?\}`)
+
+// Build documents every route of router whose path starts with prefix from the Route of the same
+// name, with errorBody as the body of error responses. Routes with a Scope accept any of
+// securitySchemes. Routes without documentation and documentation without a route are reported as
+// errors, so that the two cannot drift apart.
+func Build(router *mux.Router, info Info, prefix string, errorBody any, routes []Route, securitySchemes map[string]SecurityScheme) (Document, error) {
+	doc := Document{
+		OpenAPI:    Version,
+		Info:       info,
+		Paths:      map[string]PathItem{},
+		Components: Components{Schemas: map[string]*Schema{}, SecuritySchemes: securitySchemes},
+	}
+	schemas := newSchemas(doc.Components.Schemas)
+
+	documented := map[string]Route{}
+	for _, route := range routes {
+		documented[route.Name] = route
+	}
+
+	var errs []error
+	registered := map[string]bool{}
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil || !strings.HasPrefix(path, prefix) {
+			return nil
+		}
+
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+
+		docs, ok := documented[route.GetName()]
+		if !ok {
+			errs = append(errs, fmt.Errorf("route %s %s is not documented", strings.Join(methods, ","), path))
+			return nil
+		}
+		registered[docs.Name] = true
+
+		item := doc.Paths[openAPIPath(path)]
+		if item == nil {
+			item = PathItem{}
+			doc.Paths[openAPIPath(path)] = item
+		}
+
+		for _, method := range methods {
+			item[strings.ToLower(method)] = newOperation(schemas, path, docs, errorBody, securitySchemes)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return Document{}, err
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(documented)) {
+		if !registered[name] {
+			errs = append(errs, fmt.Errorf("documented route %s is not registered", name))
+		}
+	}
+
+	return doc, errors.Join(errs...)
+}
+
+func newOperation(schemas *schemas, path string, route Route, errorBody any, securitySchemes map[string]SecurityScheme) *Operation {
+	operation := &Operation{
+		OperationID: route.Name,
+		Summary:     route.Summary,
+		Description: route.Description,
+		Tags:        route.Tags,
+		Deprecated:  route.Deprecated,
+		Responses: map[string]Response{
+			strconv.Itoa(http.StatusOK): jsonResponse("OK", schemas.of(route.Response)),
+		},
+	}
+
+	if route.Params != nil {
+		operation.Parameters = parameters(route.Params, "path")
+	} else {
+		for _, match := range pathParam.FindAllStringSubmatch(path, -1) {
+			operation.Parameters = append(operation.Parameters, Parameter{
+				Name:     match[1],
+				In:       "path",
+				Required: true,
+				Schema:   &Schema{Type: "string"},
+			})
+		}
+	}
+
+	errorSchema := schemas.of(errorBody)
+	if route.Query != nil {
+		operation.Parameters = append(operation.Parameters, parameters(route.Query, "query")...)
+	}
+
+	if route.Params != nil || route.Query != nil {
+		operation.Responses[strconv.Itoa(http.StatusBadRequest)] = jsonResponse("Invalid request", errorSchema)
+	}
+	if route.Scope != "" && len(securitySchemes) > 0 {
+		// Scopes are only part of OAuth2 requirements, so they are documented in prose.
+		operation.Description = strings.TrimSpace(operation.Description + " Requires the " + route.Scope + " scope.")
+		for _, name := range slices.Sorted(maps.Keys(securitySchemes)) {
+			operation.Security = append(operation.Security, map[string][]string{name: {}})
+		}
+		operation.Responses[strconv.Itoa(http.StatusUnauthorized)] = jsonResponse("Missing or invalid credentials", errorSchema)
+		operation.Responses[strconv.Itoa(http.StatusForbidden)] = jsonResponse("Missing the "+route.Scope+" scope", errorSchema)
+	}
+	operation.Responses[strconv.Itoa(http.StatusGatewayTimeout)] = jsonResponse("Request deadline exceeded", errorSchema)
+	operation.Responses["default"] = jsonResponse("Error", errorSchema)
+
+	return operation
+}
+
+func jsonResponse(description string, schema *Schema) Response {
+	return Response{
+		Description: description,
+		Content:     map[string]MediaType{"application/json": {Schema: schema}},
+	}
+}
+
+// openAPIPath drops mux patterns from path variables, e.g. {gid:[0-9]+} becomes {gid}.
+func openAPIPath(path string) string {
+	return pathParam.ReplaceAllString(path, "{$1}")
+}
+
+// parameters documents the fields of v bound from the `query` or `path` tag.
+func parameters(v any, in string) []Parameter {
+	var parameters []Parameter
+	for _, field := range validation.Fields(v, in) {
+		// Optional parameters are simply left out, a pointer field is not a nullable parameter.
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+
+		parameter := Parameter{
+			Name:     field.Name,
+			In:       in,
+			Required: in == "path",
+			Schema:   typeSchema(fieldType),
+		}
+
+		if field.Default != "" {
+			parameter.Schema.Default = parseDefault(parameter.Schema.Type, field.Default)
+		}
+
+		for _, rule := range field.Rules {
+			applyRule(&parameter, rule)
+		}
+
+		parameters = append(parameters, parameter)
+	}
+
+	return parameters
+}
+
+func applyRule(parameter *Parameter, rule validation.Rule) {
+	schema := parameter.Schema
+
+	switch rule.Name {
+	case "required":
+		parameter.Required = true
+	case "min", "max":
+		bound, _ := strconv.Atoi(rule.Arg)
+		switch {
+		case schema.Type == "string" && rule.Name == "min":
+			schema.MinLength = &bound
+		case schema.Type == "string":
+			schema.MaxLength = &bound
+		case rule.Name == "min":
+			schema.Minimum = &bound
+		default:
+			schema.Maximum = &bound
+		}
+	case "gid":
+		schema.Pattern = `^[0-9]+$`
+	case "user":
+		schema.Pattern = `^([0-9]+|me|[^@\s]+@[^@\s]+)$`
+		parameter.Description = "A user gid, `me` or an email address."
+	case "oneof":
+		schema.Enum = strings.Fields(rule.Arg)
+	case "excludes":
+		parameter.Description = "Cannot be combined with " + rule.Arg + "."
+	}
+}
+
+func parseDefault(schemaType, value string) any {
+	switch schemaType {
+	case "integer":
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+
+	return value
+}
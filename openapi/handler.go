@@ -0,0 +1,45 @@
+package openapi
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/cyber/test-project/transport"
+)
+
+// Handler serves the document as JSON.
+func Handler(doc Document) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		transport.SendJson(r.Context(), w, http.StatusOK, doc)
+	}
+}
+
+var uiTemplate = template.Must(template.New("ui").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>{{.Title}}</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.onload = () => {
+      window.ui = SwaggerUIBundle({url: {{.SpecURL}}, dom_id: "#swagger-ui"});
+    };
+  </script>
+</body>
+</html>
+`))
+
+// UIHandler serves a Swagger UI page, loaded from unpkg, for the document served at specURL.
+func UIHandler(title, specURL string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		err := uiTemplate.Execute(w, struct{ Title, SpecURL string }{title, specURL})
+		if err != nil {
+			transport.SendError(r.Context(), w, err)
+		}
+	}
+}
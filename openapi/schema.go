@@ -0,0 +1,139 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Minimum              *int               `json:"minimum,omitempty"`
+	Maximum              *int               `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Default              any                `json:"default,omitempty"`
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// schemas describes Go types as they are encoded by encoding/json. Named struct types become
+// components referenced by name.
+type schemas struct {
+	components map[string]*Schema
+}
+
+func newSchemas(components map[string]*Schema) *schemas {
+	return &schemas{components: components}
+}
+
+func (s *schemas) of(v any) *Schema {
+	if v == nil {
+		return &Schema{}
+	}
+
+	return s.schema(reflect.TypeOf(v))
+}
+
+func (s *schemas) schema(t reflect.Type) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Kind() == reflect.Pointer:
+		schema := s.schema(t.Elem())
+		if schema.Ref != "" {
+			// Siblings of $ref are ignored in OpenAPI 3.0.
+			return schema
+		}
+		schema.Nullable = true
+		return schema
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		return &Schema{Type: "array", Items: s.schema(t.Elem())}
+	case t.Kind() == reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.schema(t.Elem())}
+	case t.Kind() == reflect.Struct && t.Name() != "":
+		if _, ok := s.components[t.Name()]; !ok {
+			// Registered before its properties, so that recursive types terminate.
+			s.components[t.Name()] = &Schema{}
+			*s.components[t.Name()] = *s.object(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + t.Name()}
+	case t.Kind() == reflect.Struct:
+		return s.object(t)
+	default:
+		return typeSchema(t)
+	}
+}
+
+func (s *schemas) object(t reflect.Type) *Schema {
+	object := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	s.addFields(object, t)
+
+	return object
+}
+
+// addFields adds the JSON fields of t to object, inlining embedded structs without a JSON name.
+func (s *schemas) addFields(object *Schema, t reflect.Type) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				s.addFields(object, embedded)
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		object.Properties[name] = s.schema(field.Type)
+		if !strings.Contains(options, "omitempty") {
+			object.Required = append(object.Required, name)
+		}
+	}
+}
+
+// typeSchema describes scalar types, and anything else as any value.
+func typeSchema(t reflect.Type) *Schema {
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := typeSchema(t.Elem())
+		schema.Nullable = true
+		return schema
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	default:
+		return &Schema{}
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/cyber/test-project/app"
+)
+
+// printOpenAPI prints the OpenAPI document of the service. It fails when routes and documentation
+// drift apart, so that CI can run it without starting the service.
+func printOpenAPI([]string) int {
+	doc, err := app.OpenAPIDocument()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(doc)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	return 0
+}
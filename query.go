@@ -0,0 +1,274 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/cyber/test-project/config"
+	"github.com/cyber/test-project/services"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+var outputFormats = []string{outputTable, outputJSON, outputYAML}
+
+type queryFlags struct {
+	configPath string
+	path       string
+	resource   string
+	gid        string
+	where      []string
+	fields     string
+	output     string
+}
+
+func newQueryFlags() (*flag.FlagSet, *queryFlags) {
+	flags := flag.NewFlagSet("query", flag.ExitOnError)
+	qf := &queryFlags{}
+	flags.StringVar(&qf.configPath, "config", "config.yaml", "Path to configuration file, used for data_dumper.path")
+	flags.StringVar(&qf.path, "path", "", "Dump store directory, overrides data_dumper.path")
+	flags.StringVar(&qf.resource, "type", "", "Resource type to query; lists types and counts when omitted")
+	flags.StringVar(&qf.gid, "gid", "", "Show the resource with this gid")
+	flags.Func("where", "Filter `expression` <field><op><value>, op one of =, != and ~ (contains); "+
+		"fields are dotted paths, e.g. workspaces.name~acme. Repeat to combine", func(expression string) error {
+		qf.where = append(qf.where, expression)
+		return nil
+	})
+	flags.StringVar(&qf.fields, "fields", "gid,resource_type,name", "Comma-separated fields shown as table columns")
+	flags.StringVar(&qf.output, "output", outputTable, "Output format: "+strings.Join(outputFormats, ", "))
+
+	return flags, qf
+}
+
+func (qf *queryFlags) store() (*services.DumpStore, error) {
+	if qf.path != "" {
+		return services.NewDumpStore(qf.path), nil
+	}
+
+	cfg, err := config.ReadConfig(qf.configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return services.NewDumpStore(cfg.DataDumper.Path), nil
+}
+
+func runQuery(args []string) int {
+	flags, qf := newQueryFlags()
+	_ = flags.Parse(args)
+
+	err := query(os.Stdout, qf)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "query failed: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+func query(w io.Writer, qf *queryFlags) error {
+	if !isOutputFormat(qf.output) {
+		return fmt.Errorf("unsupported output %q, expected one of %s", qf.output, strings.Join(outputFormats, ", "))
+	}
+
+	var filters []services.Filter
+	for _, expression := range qf.where {
+		filter, err := services.ParseFilter(expression)
+		if err != nil {
+			return err
+		}
+		filters = append(filters, filter)
+	}
+
+	store, err := qf.store()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case qf.gid != "":
+		if qf.resource == "" {
+			return errors.New("-gid requires -type")
+		}
+
+		resource, err := store.Get(qf.resource, qf.gid)
+		if err != nil {
+			return fmt.Errorf("%s %s: %w", qf.resource, qf.gid, err)
+		}
+
+		return writeResource(w, qf.output, resource)
+	case qf.resource != "" || len(filters) > 0:
+		types := []string{qf.resource}
+		if qf.resource == "" {
+			types, err = storedTypes(store)
+			if err != nil {
+				return err
+			}
+		}
+
+		var resources []map[string]any
+		for _, resourceType := range types {
+			found, err := store.List(resourceType, filters...)
+			if err != nil {
+				return err
+			}
+			resources = append(resources, found...)
+		}
+
+		return writeResources(w, qf.output, splitList(qf.fields), resources)
+	default:
+		types, err := store.Types()
+		if err != nil {
+			return err
+		}
+
+		return writeTypes(w, qf.output, types)
+	}
+}
+
+func storedTypes(store *services.DumpStore) ([]string, error) {
+	counts, err := store.Types()
+	if err != nil {
+		return nil, err
+	}
+
+	types := make([]string, 0, len(counts))
+	for _, count := range counts {
+		types = append(types, count.Type)
+	}
+
+	return types, nil
+}
+
+func writeTypes(w io.Writer, output string, types []services.ResourceTypeCount) error {
+	if output != outputTable {
+		return encode(w, output, types)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TYPE\tCOUNT")
+	for _, count := range types {
+		fmt.Fprintf(tw, "%s\t%d\n", count.Type, count.Count)
+	}
+
+	return tw.Flush()
+}
+
+func writeResource(w io.Writer, output string, resource map[string]any) error {
+	if output != outputTable {
+		return encode(w, output, resource)
+	}
+
+	rows := map[string]string{}
+	flatten("", resource, rows)
+
+	keys := make([]string, 0, len(rows))
+	for key := range rows {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FIELD\tVALUE")
+	for _, key := range keys {
+		fmt.Fprintf(tw, "%s\t%s\n", key, rows[key])
+	}
+
+	return tw.Flush()
+}
+
+func writeResources(w io.Writer, output string, fields []string, resources []map[string]any) error {
+	if output != outputTable {
+		if resources == nil {
+			resources = []map[string]any{}
+		}
+		return encode(w, output, resources)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.ToUpper(strings.Join(fields, "\t")))
+	for _, resource := range resources {
+		columns := make([]string, 0, len(fields))
+		for _, field := range fields {
+			var values []string
+			for _, value := range services.Lookup(resource, strings.Split(field, ".")) {
+				values = append(values, services.FormatValue(value))
+			}
+			columns = append(columns, strings.Join(values, ", "))
+		}
+		fmt.Fprintln(tw, strings.Join(columns, "\t"))
+	}
+
+	return tw.Flush()
+}
+
+// flatten turns nested objects and arrays into dotted keys, e.g. workspaces.0.name.
+func flatten(prefix string, value any, rows map[string]string) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, nested := range v {
+			flatten(joinKey(prefix, key), nested, rows)
+		}
+	case []any:
+		for i, nested := range v {
+			flatten(joinKey(prefix, fmt.Sprint(i)), nested, rows)
+		}
+	default:
+		rows[prefix] = services.FormatValue(v)
+	}
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+
+	return prefix + "." + key
+}
+
+func encode(w io.Writer, output string, value any) error {
+	if output == outputYAML {
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		return encoder.Encode(value)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+func isOutputFormat(output string) bool {
+	for _, format := range outputFormats {
+		if format == output {
+			return true
+		}
+	}
+
+	return false
+}
+
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
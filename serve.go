@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"syscall"
+
+	"github.com/cyber/test-project/app"
+	"github.com/cyber/test-project/shutdown"
+)
+
+func newServeFlags() (*flag.FlagSet, *string) {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	configPath := flags.String("config", "config.yaml", "Path to configuration file")
+
+	return flags, configPath
+}
+
+func serve(args []string) int {
+	flags, configPath := newServeFlags()
+	_ = flags.Parse(args)
+
+	application, err := app.InitApplication(*configPath)
+	if err != nil {
+		log.Printf("Failed to initialize application: %v", err)
+		return 1
+	}
+
+	ctx, cancel := shutdown.ListenForSignals(context.Background(), []os.Signal{os.Interrupt, syscall.SIGTERM}, application)
+	defer cancel()
+
+	err = application.Run(ctx)
+	if err != nil {
+		log.Printf("Application stopped with error: %v", err)
+		return 1
+	}
+
+	return 0
+}
@@ -2,8 +2,14 @@ package services
 
 import (
 	"context"
+	"net/http"
+	"sync"
 
+	"go.uber.org/zap"
+
+	"github.com/cyber/test-project/appcontext"
 	"github.com/cyber/test-project/clients"
+	"github.com/cyber/test-project/config"
 	"github.com/cyber/test-project/models"
 )
 
@@ -11,44 +17,147 @@ type AsanaUsersGetter interface {
 	GetUsers(context.Context, clients.GetUsersRequest) (models.AsanaGetUsersResponse, error)
 }
 
+type AsanaUserGetter interface {
+	GetUser(context.Context, clients.GetUserRequest) (models.AsanaGetUserResponse, error)
+}
+
 type AsanaProjectsGetter interface {
 	GetProjects(context.Context, clients.GetProjectsRequest) (models.AsanaGetProjectsResponse, error)
 }
 
+type AsanaProjectGetter interface {
+	GetProject(context.Context, clients.GetProjectRequest) (models.AsanaGetProjectResponse, error)
+}
+
+const asanaServiceName = "asana"
+
 type AsanaService struct {
 	client      *clients.AsanaClient
+	tokenMu     sync.RWMutex
 	accessToken string
 	dataDumper  Dumper
+	requests    *requestSharing
 }
 
-func NewAsanaService(client *clients.AsanaClient, accessToken string, dumper Dumper) *AsanaService {
+func NewAsanaService(client *clients.AsanaClient, cfg config.AsanaConfig, dumper Dumper) *AsanaService {
 	return &AsanaService{
 		client:      client,
-		accessToken: accessToken,
+		accessToken: cfg.AccessToken,
 		dataDumper:  dumper,
+		requests:    newRequestSharing(cfg.Coalescing, cfg.Hedging),
 	}
 }
 
-func (a AsanaService) GetUsers(ctx context.Context, request clients.GetUsersRequest) (models.AsanaGetUsersResponse, error) {
-	request.Token = a.accessToken
-	response, err := a.client.GetUsers(ctx, request)
+func (a *AsanaService) SetAccessToken(token string) {
+	a.tokenMu.Lock()
+	defer a.tokenMu.Unlock()
+
+	a.accessToken = token
+}
+
+// token returns the token bound to ctx, e.g. for a tenant, or else the configured one.
+func (a *AsanaService) token(ctx context.Context) string {
+	if token := appcontext.Token(ctx); token != "" {
+		return token
+	}
+
+	a.tokenMu.RLock()
+	defer a.tokenMu.RUnlock()
+
+	return a.accessToken
+}
+
+// GetUsers shares the upstream call with identical in-flight requests and dumps its result once.
+func (a *AsanaService) GetUsers(ctx context.Context, request clients.GetUsersRequest) (models.AsanaGetUsersResponse, error) {
+	request.Token = a.token(ctx)
+	key := request.Token + "\x00" + request.Query().Encode()
+
+	response, first, err := shared(ctx, a.requests, ResourceUsers, key, func(ctx context.Context) (models.AsanaGetUsersResponse, error) {
+		return a.client.GetUsers(ctx, request)
+	})
 	if err != nil {
 		return models.AsanaGetUsersResponse{}, err
 	}
 
-	a.dataDumper.DumpAny(ctx, response.Data.ToTypedResourcesSlice())
+	if first {
+		a.dump(ctx, request.Path(), response.Data.ToTypedResourcesSlice())
+	}
 
 	return response, nil
 }
 
-func (a AsanaService) GetProjects(ctx context.Context, request clients.GetProjectsRequest) (models.AsanaGetProjectsResponse, error) {
-	request.Token = a.accessToken
-	response, err := a.client.GetProjects(ctx, request)
+// GetProjects shares the upstream call with identical in-flight requests and dumps its result once.
+func (a *AsanaService) GetProjects(ctx context.Context, request clients.GetProjectsRequest) (models.AsanaGetProjectsResponse, error) {
+	request.Token = a.token(ctx)
+	key := request.Token + "\x00" + request.Query().Encode()
+
+	response, first, err := shared(ctx, a.requests, ResourceProjects, key, func(ctx context.Context) (models.AsanaGetProjectsResponse, error) {
+		return a.client.GetProjects(ctx, request)
+	})
 	if err != nil {
 		return models.AsanaGetProjectsResponse{}, err
 	}
 
-	a.dataDumper.DumpAny(ctx, response.Data.ToTypedResourcesSlice())
+	if first {
+		a.dump(ctx, request.Path(), response.Data.ToTypedResourcesSlice())
+	}
+
+	return response, nil
+}
+
+// GetUser shares the upstream call with identical in-flight requests and dumps its result once.
+func (a *AsanaService) GetUser(ctx context.Context, request clients.GetUserRequest) (models.AsanaGetUserResponse, error) {
+	request.Token = a.token(ctx)
+	key := request.Token + "\x00gid=" + request.Gid
+
+	response, first, err := shared(ctx, a.requests, ResourceUsers, key, func(ctx context.Context) (models.AsanaGetUserResponse, error) {
+		return a.client.GetUser(ctx, request)
+	})
+	if err != nil {
+		return models.AsanaGetUserResponse{}, err
+	}
+
+	if first {
+		a.dump(ctx, request.Path(), []models.TypedResource{response.Data})
+	}
 
 	return response, nil
 }
+
+// GetProject shares the upstream call with identical in-flight requests and dumps its result once.
+func (a *AsanaService) GetProject(ctx context.Context, request clients.GetProjectRequest) (models.AsanaGetProjectResponse, error) {
+	request.Token = a.token(ctx)
+	key := request.Token + "\x00gid=" + request.Gid
+
+	response, first, err := shared(ctx, a.requests, ResourceProjects, key, func(ctx context.Context) (models.AsanaGetProjectResponse, error) {
+		return a.client.GetProject(ctx, request)
+	})
+	if err != nil {
+		return models.AsanaGetProjectResponse{}, err
+	}
+
+	if first {
+		a.dump(ctx, request.Path(), []models.TypedResource{response.Data})
+	}
+
+	return response, nil
+}
+
+// dump writes the resources returned by the GET of path, logging with the fields of that call, so
+// that dumps can be correlated with the upstream request that produced them.
+func (a *AsanaService) dump(ctx context.Context, path string, resources []models.TypedResource) {
+	logger := appcontext.Logger(ctx).With(
+		zap.String("service", asanaServiceName),
+		zap.String("method", http.MethodGet),
+		zap.String("path", path),
+	)
+
+	_ = a.dataDumper.DumpAny(appcontext.WithLogger(ctx, logger), resources)
+}
+
+// Ping verifies that Asana is reachable and the configured token is accepted.
+func (a *AsanaService) Ping(ctx context.Context) error {
+	_, err := a.client.GetMe(ctx, clients.GetMeRequest{Token: a.token(ctx)})
+
+	return err
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/cyber/test-project/appcontext"
+	"github.com/cyber/test-project/clients"
+	"github.com/cyber/test-project/logging"
+	"github.com/cyber/test-project/models"
+)
+
+const (
+	ResourceUsers    = "users"
+	ResourceProjects = "projects"
+
+	DefaultCrawlPageSize = 100
+)
+
+var ResourceTypes = []string{ResourceUsers, ResourceProjects}
+
+type CrawlRequest struct {
+	Workspace string
+	Types     []string
+	PageSize  int
+}
+
+type CrawlSummary struct {
+	Resources map[string]int
+	Pages     int
+	Duration  time.Duration
+}
+
+// PageHandler receives every page fetched by Crawl, including empty ones.
+type PageHandler func(ctx context.Context, resourceType string, resources []models.TypedResource) error
+
+// Crawl fetches every page of the requested resource types and passes them to handle. It stops at the
+// first fetch or handler error and returns what was crawled so far.
+func (a *AsanaService) Crawl(ctx context.Context, request CrawlRequest, handle PageHandler) (CrawlSummary, error) {
+	started := time.Now()
+	summary := CrawlSummary{Resources: map[string]int{}}
+
+	for _, resourceType := range request.Types {
+		if !isResourceType(resourceType) {
+			return summary, models.ErrUnsupportedResourceType{ResourceType: resourceType}
+		}
+	}
+
+	pageSize := request.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultCrawlPageSize
+	}
+
+	logger := logging.ForComponent(appcontext.Logger(ctx), "services").With(zap.String("operation", "crawl"))
+
+	for _, resourceType := range request.Types {
+		offset := ""
+		for {
+			resources, nextOffset, err := a.fetchPage(ctx, resourceType, request.Workspace, pageSize, offset)
+			if err != nil {
+				summary.Duration = time.Since(started)
+				return summary, err
+			}
+
+			summary.Pages++
+			summary.Resources[resourceType] += len(resources)
+
+			err = handle(ctx, resourceType, resources)
+			if err != nil {
+				summary.Duration = time.Since(started)
+				return summary, err
+			}
+
+			if nextOffset == "" {
+				break
+			}
+			offset = nextOffset
+		}
+
+		logger.Info("crawled resources",
+			zap.String("resource_type", resourceType),
+			zap.Int("count", summary.Resources[resourceType]),
+		)
+	}
+
+	summary.Duration = time.Since(started)
+
+	return summary, nil
+}
+
+// DumpPage is a PageHandler that writes pages to the dump store.
+func (a *AsanaService) DumpPage(ctx context.Context, _ string, resources []models.TypedResource) error {
+	return a.dataDumper.DumpAny(ctx, resources)
+}
+
+func (a *AsanaService) fetchPage(ctx context.Context, resourceType, workspace string, limit int, offset string) ([]models.TypedResource, string, error) {
+	token := a.token(ctx)
+
+	switch resourceType {
+	case ResourceUsers:
+		response, err := a.client.GetUsers(ctx, clients.GetUsersRequest{
+			Workspace: workspace,
+			Limit:     limit,
+			Offset:    offset,
+			Token:     token,
+		})
+		if err != nil {
+			return nil, "", err
+		}
+
+		return response.Data.ToTypedResourcesSlice(), response.NextPage.Offset, nil
+	case ResourceProjects:
+		response, err := a.client.GetProjects(ctx, clients.GetProjectsRequest{
+			Workspace: workspace,
+			Limit:     limit,
+			Offset:    offset,
+			Token:     token,
+		})
+		if err != nil {
+			return nil, "", err
+		}
+
+		return response.Data.ToTypedResourcesSlice(), response.NextPage.Offset, nil
+	default:
+		return nil, "", models.ErrUnsupportedResourceType{ResourceType: resourceType}
+	}
+}
+
+func isResourceType(resourceType string) bool {
+	for _, known := range ResourceTypes {
+		if known == resourceType {
+			return true
+		}
+	}
+
+	return false
+}
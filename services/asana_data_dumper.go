@@ -3,14 +3,22 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"sync"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
+	"github.com/cyber/test-project/appcontext"
 	"github.com/cyber/test-project/config"
 	"github.com/cyber/test-project/logging"
+	"github.com/cyber/test-project/metrics"
 	"github.com/cyber/test-project/models"
+	"github.com/cyber/test-project/tracing"
 )
 
 type TypedResourcesSliceConverter interface {
@@ -18,11 +26,13 @@ type TypedResourcesSliceConverter interface {
 }
 
 type Dumper interface {
-	DumpAny(ctx context.Context, resources []models.TypedResource)
+	DumpAny(ctx context.Context, resources []models.TypedResource) error
 }
 
 type AsanaDataDumper struct {
-	cfg config.DataDumperConfig
+	mu       sync.RWMutex
+	cfg      config.DataDumperConfig
+	inFlight sync.WaitGroup
 }
 
 func NewAsanaDataDumper(cfg config.DataDumperConfig) *AsanaDataDumper {
@@ -31,38 +41,114 @@ func NewAsanaDataDumper(cfg config.DataDumperConfig) *AsanaDataDumper {
 	}
 }
 
-func (d AsanaDataDumper) DumpAny(ctx context.Context, resources []models.TypedResource) {
-	logger := logging.FromContext(ctx).With(zap.String("operation", "dump_resources"))
+func (d *AsanaDataDumper) Update(cfg config.DataDumperConfig) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
 
+	d.cfg = cfg
+}
+
+// Close waits for dumps that are still being written, or until ctx is done.
+func (d *AsanaDataDumper) Close(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		d.inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// CheckWritable verifies that dumps can be written by creating and removing a probe file.
+func (d *AsanaDataDumper) CheckWritable(context.Context) error {
+	d.mu.RLock()
+	basePath := d.cfg.Path
+	d.mu.RUnlock()
+
+	err := os.MkdirAll(basePath, 0755)
+	if err != nil {
+		return err
+	}
+
+	fh, err := os.CreateTemp(basePath, ".health-*")
+	if err != nil {
+		return err
+	}
+
+	return errors.Join(fh.Close(), os.Remove(fh.Name()))
+}
+
+// DumpAny writes every resource to the dump store. Failures are logged per resource and returned joined.
+func (d *AsanaDataDumper) DumpAny(ctx context.Context, resources []models.TypedResource) error {
+	d.inFlight.Add(1)
+	defer d.inFlight.Done()
+
+	logger := logging.ForComponent(appcontext.Logger(ctx), "services").With(zap.String("operation", "dump_resources"))
+
+	d.mu.RLock()
+	basePath := d.cfg.Path
+	d.mu.RUnlock()
+
+	var errs []error
 	for _, res := range resources {
-		path := fmt.Sprintf("%s/%s", d.cfg.Path, res.GetResourceType())
-		err := os.MkdirAll(path, 0755)
+		resCtx, span := tracing.Start(ctx, "dump "+res.GetResourceType(), trace.WithAttributes(
+			attribute.String("resource.type", res.GetResourceType()),
+			attribute.String("resource.gid", res.GetGid()),
+		))
+
+		err := dumpResource(resCtx, logger, basePath, res)
 		if err != nil {
-			logger.Warn("Failed to create directory", zap.String("path", path))
-			continue
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			metrics.IncDumpedResources(res.GetResourceType(), metrics.DumpFailed)
+			errs = append(errs, fmt.Errorf("dump %s %s: %w", res.GetResourceType(), res.GetGid(), err))
+		} else {
+			metrics.IncDumpedResources(res.GetResourceType(), metrics.DumpWritten)
 		}
 
-		pathFn := fmt.Sprintf("%s/%s.json", path, res.GetGid())
+		span.End()
+	}
 
-		encoded, err := json.Marshal(res)
-		if err != nil {
-			logger.Warn("failed to marshal resource", zap.String("resource", res.GetGid()), zap.Error(err))
-			continue
-		}
+	logger.Debug("dumped resources", zap.Int("count", len(resources)-len(errs)), zap.Int("failed", len(errs)))
 
-		fh, err := os.Create(pathFn)
-		if err != nil {
-			logger.Error("failed to create file", zap.String("path", pathFn), zap.Error(err))
-			continue
-		}
+	return errors.Join(errs...)
+}
 
-		_, err = fh.Write(encoded)
-		if err != nil {
-			logger.Error("failed into write file", zap.String("path", pathFn), zap.Error(err))
-		}
+func dumpResource(ctx context.Context, logger *zap.Logger, basePath string, res models.TypedResource) error {
+	path := fmt.Sprintf("%s/%s", basePath, res.GetResourceType())
+	err := os.MkdirAll(path, 0755)
+	if err != nil {
+		logger.Warn("Failed to create directory", zap.String("path", path))
+		return err
+	}
+
+	pathFn := fmt.Sprintf("%s/%s.json", path, res.GetGid())
+
+	encoded, err := json.Marshal(res)
+	if err != nil {
+		logger.Warn("failed to marshal resource", zap.String("resource", res.GetGid()), zap.Error(err))
+		return err
+	}
 
-		closeFile(ctx, fh)
+	fh, err := os.Create(pathFn)
+	if err != nil {
+		logger.Error("failed to create file", zap.String("path", pathFn), zap.Error(err))
+		return err
 	}
+	defer closeFile(ctx, fh)
+
+	_, err = fh.Write(encoded)
+	if err != nil {
+		logger.Error("failed into write file", zap.String("path", pathFn), zap.Error(err))
+		return err
+	}
+
+	return nil
 }
 
 func closeFile(ctx context.Context, fh *os.File) {
@@ -71,5 +157,5 @@ func closeFile(ctx context.Context, fh *os.File) {
 		return
 	}
 
-	logging.FromContext(ctx).Error("Failed to close file", zap.Error(err))
+	appcontext.Logger(ctx).Error("Failed to close file", zap.Error(err))
 }
@@ -0,0 +1,253 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/cyber/test-project/appcontext"
+	"github.com/cyber/test-project/asanatest"
+	"github.com/cyber/test-project/clients"
+	"github.com/cyber/test-project/config"
+	"github.com/cyber/test-project/models"
+)
+
+// recordingDumper keeps the gids of every DumpAny call.
+type recordingDumper struct {
+	mu    sync.Mutex
+	dumps [][]string
+}
+
+func (d *recordingDumper) DumpAny(_ context.Context, resources []models.TypedResource) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var gids []string
+	for _, resource := range resources {
+		gids = append(gids, resource.GetGid())
+	}
+	d.dumps = append(d.dumps, gids)
+
+	return nil
+}
+
+func (d *recordingDumper) Dumps() [][]string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return slices.Clone(d.dumps)
+}
+
+func newTestAsanaService(server *asanatest.Server, cfg config.AsanaConfig) (*AsanaService, *recordingDumper) {
+	client := clients.NewAsanaClient(clients.ClientOptions{
+		ServiceName: asanaServiceName,
+		BaseURL:     server.URL,
+	})
+	if cfg.AccessToken == "" {
+		cfg.AccessToken = asanatest.DefaultToken
+	}
+	dumper := &recordingDumper{}
+
+	return NewAsanaService(client, cfg, dumper), dumper
+}
+
+func TestAsanaServiceGetAndDump(t *testing.T) {
+	tests := []struct {
+		name      string
+		call      func(context.Context, *AsanaService) error
+		token     string
+		fault     *asanatest.Fault
+		faultPath string
+		wantErr   func(error) bool
+		wantDumps [][]string
+	}{
+		{
+			name: "users page",
+			call: func(ctx context.Context, s *AsanaService) error {
+				_, err := s.GetUsers(ctx, clients.GetUsersRequest{Workspace: "2000", Limit: 2})
+				return err
+			},
+			wantDumps: [][]string{{"1205", "1206"}},
+		},
+		{
+			name: "projects page",
+			call: func(ctx context.Context, s *AsanaService) error {
+				archived := true
+				_, err := s.GetProjects(ctx, clients.GetProjectsRequest{Workspace: "1000", Archived: &archived})
+				return err
+			},
+			wantDumps: [][]string{{"3304"}},
+		},
+		{
+			name: "user",
+			call: func(ctx context.Context, s *AsanaService) error {
+				_, err := s.GetUser(ctx, clients.GetUserRequest{Gid: "1203"})
+				return err
+			},
+			wantDumps: [][]string{{"1203"}},
+		},
+		{
+			name: "project not found",
+			call: func(ctx context.Context, s *AsanaService) error {
+				_, err := s.GetProject(ctx, clients.GetProjectRequest{Gid: "404"})
+				return err
+			},
+			wantErr: isServiceResponse(http.StatusNotFound),
+		},
+		{
+			name:  "unauthorized",
+			token: "revoked",
+			call: func(ctx context.Context, s *AsanaService) error {
+				_, err := s.GetUser(ctx, clients.GetUserRequest{Gid: "1203"})
+				return err
+			},
+			wantErr: isServiceResponse(http.StatusUnauthorized),
+		},
+		{
+			name:      "rate limited",
+			fault:     &asanatest.Fault{Status: http.StatusTooManyRequests},
+			faultPath: asanatest.UsersPath,
+			call: func(ctx context.Context, s *AsanaService) error {
+				_, err := s.GetUsers(ctx, clients.GetUsersRequest{})
+				return err
+			},
+			wantErr: func(err error) bool { return errors.As(err, &models.ErrRateLimitExceeded{}) },
+		},
+		{
+			name:      "server error",
+			fault:     &asanatest.Fault{Status: http.StatusInternalServerError},
+			faultPath: asanatest.ProjectsPath,
+			call: func(ctx context.Context, s *AsanaService) error {
+				_, err := s.GetProjects(ctx, clients.GetProjectsRequest{})
+				return err
+			},
+			wantErr: func(err error) bool { return errors.As(err, &models.ErrServiceFailure{}) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := asanatest.NewServer(asanatest.DefaultFixtures())
+			defer server.Close()
+
+			if tt.fault != nil {
+				server.InjectFault(tt.faultPath, *tt.fault)
+			}
+
+			service, dumper := newTestAsanaService(server, config.AsanaConfig{AccessToken: tt.token})
+
+			err := tt.call(context.Background(), service)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if tt.wantErr != nil && !tt.wantErr(err) {
+				t.Fatalf("error = %#v", err)
+			}
+
+			if dumps := dumper.Dumps(); !slices.EqualFunc(dumps, tt.wantDumps, slices.Equal) {
+				t.Errorf("dumps = %v, want %v", dumps, tt.wantDumps)
+			}
+		})
+	}
+}
+
+func TestAsanaServiceCrawlFollowsNextPage(t *testing.T) {
+	tests := []struct {
+		name        string
+		request     CrawlRequest
+		fault       *asanatest.Fault
+		wantPages   int
+		wantCounts  map[string]int
+		wantPageErr bool
+	}{
+		{
+			name:       "all pages",
+			request:    CrawlRequest{Workspace: "1000", Types: ResourceTypes, PageSize: 2},
+			wantPages:  3 + 2,
+			wantCounts: map[string]int{ResourceUsers: 5, ResourceProjects: 4},
+		},
+		{
+			name:       "single page",
+			request:    CrawlRequest{Types: []string{ResourceUsers}},
+			wantPages:  1,
+			wantCounts: map[string]int{ResourceUsers: 7},
+		},
+		{
+			name:        "stops at the first error",
+			request:     CrawlRequest{Types: ResourceTypes, PageSize: 5},
+			fault:       &asanatest.Fault{Status: http.StatusBadGateway},
+			wantPages:   2,
+			wantCounts:  map[string]int{ResourceUsers: 7},
+			wantPageErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := asanatest.NewServer(asanatest.DefaultFixtures())
+			defer server.Close()
+
+			if tt.fault != nil {
+				server.InjectFault(asanatest.ProjectsPath, *tt.fault)
+			}
+
+			service, dumper := newTestAsanaService(server, config.AsanaConfig{})
+
+			summary, err := service.Crawl(context.Background(), tt.request, service.DumpPage)
+			if (err != nil) != tt.wantPageErr {
+				t.Fatalf("Crawl() error = %v, want error %t", err, tt.wantPageErr)
+			}
+
+			if summary.Pages != tt.wantPages || len(dumper.Dumps()) != tt.wantPages {
+				t.Errorf("pages = %d, dumps = %d, want %d", summary.Pages, len(dumper.Dumps()), tt.wantPages)
+			}
+			for resourceType, want := range tt.wantCounts {
+				if got := summary.Resources[resourceType]; got != want {
+					t.Errorf("%s = %d, want %d", resourceType, got, want)
+				}
+			}
+		})
+	}
+}
+
+func isServiceResponse(statusCode int) func(error) bool {
+	return func(err error) bool {
+		var responseErr models.ErrServiceResponse
+		return errors.As(err, &responseErr) && responseErr.StatusCode == statusCode
+	}
+}
+
+func TestAsanaServiceContextToken(t *testing.T) {
+	tests := []struct {
+		name        string
+		configToken string
+		ctxToken    string
+		wantErr     bool
+	}{
+		{name: "configured token", configToken: asanatest.DefaultToken},
+		{name: "context token wins", configToken: "revoked", ctxToken: asanatest.DefaultToken},
+		{name: "revoked context token", configToken: asanatest.DefaultToken, ctxToken: "revoked", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := asanatest.NewServer(asanatest.DefaultFixtures())
+			defer server.Close()
+
+			service, _ := newTestAsanaService(server, config.AsanaConfig{AccessToken: tt.configToken})
+
+			ctx := context.Background()
+			if tt.ctxToken != "" {
+				ctx = appcontext.WithToken(ctx, tt.ctxToken)
+			}
+
+			_, err := service.GetUser(ctx, clients.GetUserRequest{Gid: "1201"})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetUser() error = %v, want error %t", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/cyber/test-project/models"
+)
+
+const (
+	ExportFormatJSON   = "json"
+	ExportFormatNDJSON = "ndjson"
+)
+
+// BulkExporter writes crawled resources into one file per resource type, either as a single JSON
+// array or as newline-delimited JSON.
+type BulkExporter struct {
+	dir    string
+	format string
+	files  map[string]*exportFile
+}
+
+type exportFile struct {
+	fh     *os.File
+	writer *bufio.Writer
+	count  int
+}
+
+func NewBulkExporter(dir, format string) (*BulkExporter, error) {
+	if format != ExportFormatJSON && format != ExportFormatNDJSON {
+		return nil, models.ErrUnsupportedExportFormat{Format: format}
+	}
+
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BulkExporter{
+		dir:    dir,
+		format: format,
+		files:  map[string]*exportFile{},
+	}, nil
+}
+
+// Path returns the file resources of the given type are exported to.
+func (e *BulkExporter) Path(resourceType string) string {
+	return filepath.Join(e.dir, resourceType+"."+e.format)
+}
+
+// WritePage is a PageHandler appending a page to the export file of its resource type.
+func (e *BulkExporter) WritePage(_ context.Context, resourceType string, resources []models.TypedResource) error {
+	file, err := e.file(resourceType)
+	if err != nil {
+		return err
+	}
+
+	for _, res := range resources {
+		encoded, err := json.Marshal(res)
+		if err != nil {
+			return err
+		}
+
+		if e.format == ExportFormatJSON && file.count > 0 {
+			_, err = file.writer.WriteString(",\n")
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = file.writer.Write(encoded)
+		if err != nil {
+			return err
+		}
+
+		if e.format == ExportFormatNDJSON {
+			err = file.writer.WriteByte('\n')
+			if err != nil {
+				return err
+			}
+		}
+
+		file.count++
+	}
+
+	return nil
+}
+
+// Close finishes and closes every export file.
+func (e *BulkExporter) Close() error {
+	var errs []error
+	for resourceType, file := range e.files {
+		if e.format == ExportFormatJSON {
+			_, err := file.writer.WriteString("\n]\n")
+			errs = append(errs, err)
+		}
+
+		errs = append(errs, file.writer.Flush(), file.fh.Close())
+		delete(e.files, resourceType)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (e *BulkExporter) file(resourceType string) (*exportFile, error) {
+	if file, ok := e.files[resourceType]; ok {
+		return file, nil
+	}
+
+	fh, err := os.Create(e.Path(resourceType))
+	if err != nil {
+		return nil, err
+	}
+
+	file := &exportFile{fh: fh, writer: bufio.NewWriter(fh)}
+	if e.format == ExportFormatJSON {
+		_, err = file.writer.WriteString("[\n")
+		if err != nil {
+			return nil, errors.Join(err, fh.Close())
+		}
+	}
+
+	e.files[resourceType] = file
+
+	return file, nil
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cyber/test-project/models"
+)
+
+const (
+	FilterEquals    = "="
+	FilterNotEquals = "!="
+	FilterContains  = "~"
+)
+
+// Filter matches resources by a dotted field path, e.g. `workspaces.name~acme`. Paths through
+// arrays match when any element matches; `!=` matches when no element is equal.
+type Filter struct {
+	Path  []string
+	Op    string
+	Value string
+}
+
+func ParseFilter(expression string) (Filter, error) {
+	for _, op := range []string{FilterNotEquals, FilterContains, FilterEquals} {
+		field, value, found := strings.Cut(expression, op)
+		field = strings.TrimSpace(field)
+		if !found || field == "" {
+			continue
+		}
+
+		return Filter{
+			Path:  strings.Split(field, "."),
+			Op:    op,
+			Value: value,
+		}, nil
+	}
+
+	return Filter{}, models.ErrInvalidFilter{Expression: expression}
+}
+
+func (f Filter) Match(resource map[string]any) bool {
+	values := Lookup(resource, f.Path)
+
+	switch f.Op {
+	case FilterNotEquals:
+		for _, value := range values {
+			if FormatValue(value) == f.Value {
+				return false
+			}
+		}
+		return true
+	case FilterContains:
+		for _, value := range values {
+			if strings.Contains(strings.ToLower(FormatValue(value)), strings.ToLower(f.Value)) {
+				return true
+			}
+		}
+		return false
+	default:
+		for _, value := range values {
+			if FormatValue(value) == f.Value {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func matchAll(resource map[string]any, filters []Filter) bool {
+	for _, filter := range filters {
+		if !filter.Match(resource) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Lookup returns every value found at path, descending into each element of arrays on the way.
+func Lookup(value any, path []string) []any {
+	if array, ok := value.([]any); ok {
+		var values []any
+		for _, element := range array {
+			values = append(values, Lookup(element, path)...)
+		}
+		return values
+	}
+
+	if len(path) == 0 {
+		return []any{value}
+	}
+
+	object, ok := value.(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	next, ok := object[path[0]]
+	if !ok {
+		return nil
+	}
+
+	return Lookup(next, path[1:])
+}
+
+// FormatValue renders a decoded JSON value as plain text: scalars as is, objects and arrays as JSON.
+func FormatValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(encoded)
+	}
+}
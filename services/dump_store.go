@@ -0,0 +1,125 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DumpStore reads resources written by AsanaDataDumper from `<path>/<type>/<gid>.json`.
+type DumpStore struct {
+	path string
+}
+
+type ResourceTypeCount struct {
+	Type  string `json:"type" yaml:"type"`
+	Count int    `json:"count" yaml:"count"`
+}
+
+// ErrResourceNotFound is returned when the requested resource was never dumped.
+var ErrResourceNotFound = errors.New("resource not found")
+
+func NewDumpStore(path string) *DumpStore {
+	return &DumpStore{path: path}
+}
+
+// Types lists every dumped resource type with the number of stored resources, sorted by type.
+func (s *DumpStore) Types() ([]ResourceTypeCount, error) {
+	entries, err := os.ReadDir(s.path)
+	if err != nil {
+		return nil, err
+	}
+
+	var types []ResourceTypeCount
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		gids, err := s.Gids(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		types = append(types, ResourceTypeCount{Type: entry.Name(), Count: len(gids)})
+	}
+
+	return types, nil
+}
+
+// Gids lists the gids of every dumped resource of resourceType, sorted.
+func (s *DumpStore) Gids(resourceType string) ([]string, error) {
+	entries, err := os.ReadDir(s.typePath(resourceType))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var gids []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || filepath.Ext(name) != ".json" {
+			continue
+		}
+
+		gids = append(gids, strings.TrimSuffix(name, ".json"))
+	}
+	sort.Strings(gids)
+
+	return gids, nil
+}
+
+// Get decodes one dumped resource into a generic JSON value.
+func (s *DumpStore) Get(resourceType, gid string) (map[string]any, error) {
+	if gid == "" || strings.ContainsAny(gid, `/\`) || strings.HasPrefix(gid, ".") {
+		return nil, ErrResourceNotFound
+	}
+
+	encoded, err := os.ReadFile(filepath.Join(s.typePath(resourceType), gid+".json"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrResourceNotFound
+		}
+		return nil, err
+	}
+
+	var resource map[string]any
+	err = json.Unmarshal(encoded, &resource)
+	if err != nil {
+		return nil, err
+	}
+
+	return resource, nil
+}
+
+// List returns the dumped resources of resourceType matching every filter, sorted by gid.
+func (s *DumpStore) List(resourceType string, filters ...Filter) ([]map[string]any, error) {
+	gids, err := s.Gids(resourceType)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []map[string]any
+	for _, gid := range gids {
+		resource, err := s.Get(resourceType, gid)
+		if err != nil {
+			return nil, err
+		}
+
+		if matchAll(resource, filters) {
+			resources = append(resources, resource)
+		}
+	}
+
+	return resources, nil
+}
+
+func (s *DumpStore) typePath(resourceType string) string {
+	return filepath.Join(s.path, filepath.Base(resourceType))
+}
@@ -0,0 +1,254 @@
+package services
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cyber/test-project/config"
+	"github.com/cyber/test-project/metrics"
+)
+
+// hedgingWindow is the number of recent latencies the hedging delay is computed from.
+const hedgingWindow = 100
+
+// requestSharing coalesces identical in-flight Asana calls and hedges slow ones.
+type requestSharing struct {
+	coalescing config.CoalescingConfig
+	hedging    config.HedgingConfig
+
+	mu        sync.Mutex
+	calls     map[string]*sharedCall
+	latencies map[string]*latencyWindow
+}
+
+// sharedCall is an in-flight call that callers with the same key can join.
+type sharedCall struct {
+	done        chan struct{}
+	deadline    time.Time
+	hasDeadline bool
+	value       any
+	err         error
+	// received is set by the first caller to receive a successful value.
+	received atomic.Bool
+}
+
+func newRequestSharing(coalescing config.CoalescingConfig, hedging config.HedgingConfig) *requestSharing {
+	return &requestSharing{
+		coalescing: coalescing,
+		hedging:    hedging,
+		calls:      map[string]*sharedCall{},
+		latencies:  map[string]*latencyWindow{},
+	}
+}
+
+// shared runs fn once for concurrent callers with the same resource type and key. The shared call is
+// not canceled when the caller that started it gives up, but keeps that caller's deadline so the
+// request budget still bounds it. A caller whose deadline is later than that one makes its own call
+// instead of joining, so it is not cut short by a budget smaller than its own.
+//
+// first reports whether the caller is the first to receive the value of an upstream call, so that
+// side effects of the result, such as dumps, happen once per call.
+func shared[T any](ctx context.Context, s *requestSharing, resourceType, key string, fn func(context.Context) (T, error)) (value T, first bool, err error) {
+	if !s.coalescing.Enabled {
+		value, err = hedged(ctx, s, resourceType, fn)
+		return value, err == nil, err
+	}
+
+	key = resourceType + "\x00" + key
+	deadline, hasDeadline := ctx.Deadline()
+
+	s.mu.Lock()
+	call, inFlight := s.calls[key]
+	if inFlight && !call.covers(deadline, hasDeadline) {
+		s.mu.Unlock()
+
+		value, err = hedged(ctx, s, resourceType, fn)
+		return value, err == nil, err
+	}
+	if !inFlight {
+		call = &sharedCall{done: make(chan struct{}), deadline: deadline, hasDeadline: hasDeadline}
+		s.calls[key] = call
+		go s.run(ctx, key, call, func(ctx context.Context) (any, error) {
+			return hedged(ctx, s, resourceType, fn)
+		})
+	}
+	s.mu.Unlock()
+
+	if inFlight {
+		metrics.IncCoalescedRequests(resourceType)
+	}
+
+	select {
+	case <-ctx.Done():
+		return value, false, ctx.Err()
+	case <-call.done:
+		if call.err != nil {
+			return value, false, call.err
+		}
+
+		return call.value.(T), call.received.CompareAndSwap(false, true), nil
+	}
+}
+
+// run makes the call detached from the cancellation, but not the deadline, of ctx.
+func (s *requestSharing) run(ctx context.Context, key string, call *sharedCall, fn func(context.Context) (any, error)) {
+	callCtx := context.WithoutCancel(ctx)
+	if call.hasDeadline {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithDeadline(callCtx, call.deadline)
+		defer cancel()
+	}
+
+	call.value, call.err = fn(callCtx)
+
+	s.mu.Lock()
+	delete(s.calls, key)
+	s.mu.Unlock()
+
+	close(call.done)
+}
+
+// covers reports whether a caller with the given deadline can wait for the call: the call must not
+// run out of time before the caller does.
+func (c *sharedCall) covers(deadline time.Time, hasDeadline bool) bool {
+	if !c.hasDeadline {
+		return true
+	}
+
+	return hasDeadline && !deadline.After(c.deadline)
+}
+
+// hedged runs fn and, when hedging applies to the resource type and fn has not returned within the
+// hedging delay, runs it a second time. The first successful attempt wins and the other is canceled.
+func hedged[T any](ctx context.Context, s *requestSharing, resourceType string, fn func(context.Context) (T, error)) (T, error) {
+	delay, ok := s.hedgeDelay(resourceType)
+	if !ok {
+		return timed(ctx, s, resourceType, fn)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		value T
+		err   error
+		hedge bool
+	}
+
+	// Buffered for both attempts, so the losing one never blocks.
+	results := make(chan result, 2)
+	attempt := func(hedge bool) {
+		value, err := timed(ctx, s, resourceType, fn)
+		results <- result{value: value, err: err, hedge: hedge}
+	}
+
+	go attempt(false)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	pending, hedgeSent := 1, false
+	for {
+		select {
+		case <-timer.C:
+			pending++
+			hedgeSent = true
+			go attempt(true)
+		case r := <-results:
+			pending--
+			if r.err != nil && pending > 0 {
+				continue
+			}
+
+			if hedgeSent {
+				metrics.IncHedgedRequests(resourceType, hedgeWinner(r.err, r.hedge))
+			}
+
+			return r.value, r.err
+		}
+	}
+}
+
+func hedgeWinner(err error, hedge bool) string {
+	switch {
+	case err != nil:
+		return metrics.HedgeWinnerNone
+	case hedge:
+		return metrics.HedgeWinnerHedge
+	default:
+		return metrics.HedgeWinnerPrimary
+	}
+}
+
+// timed runs fn and records its latency when it succeeds and the resource type is hedged.
+func timed[T any](ctx context.Context, s *requestSharing, resourceType string, fn func(context.Context) (T, error)) (T, error) {
+	started := time.Now()
+	value, err := fn(ctx)
+	if err == nil && s.hedges(resourceType) {
+		s.observe(resourceType, time.Since(started))
+	}
+
+	return value, err
+}
+
+func (s *requestSharing) hedges(resourceType string) bool {
+	return s.hedging.Enabled && slices.Contains(s.hedging.Resources, resourceType)
+}
+
+func (s *requestSharing) observe(resourceType string, latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	window := s.latencies[resourceType]
+	if window == nil {
+		window = &latencyWindow{}
+		s.latencies[resourceType] = window
+	}
+	window.add(latency)
+}
+
+// hedgeDelay returns the configured latency percentile of recent calls, clamped to the configured
+// bounds, once enough calls were observed.
+func (s *requestSharing) hedgeDelay(resourceType string) (time.Duration, bool) {
+	if !s.hedges(resourceType) {
+		return 0, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	window := s.latencies[resourceType]
+	if window == nil || len(window.samples) < s.hedging.MinSamples {
+		return 0, false
+	}
+
+	delay := window.percentile(s.hedging.Percentile)
+
+	return min(max(delay, s.hedging.MinDelay), s.hedging.MaxDelay), true
+}
+
+type latencyWindow struct {
+	samples []time.Duration
+	next    int
+}
+
+func (w *latencyWindow) add(latency time.Duration) {
+	if len(w.samples) < hedgingWindow {
+		w.samples = append(w.samples, latency)
+		return
+	}
+
+	w.samples[w.next] = latency
+	w.next = (w.next + 1) % hedgingWindow
+}
+
+func (w *latencyWindow) percentile(p float64) time.Duration {
+	sorted := slices.Sorted(slices.Values(w.samples))
+	i := int(math.Ceil(p*float64(len(sorted)))) - 1
+
+	return sorted[max(i, 0)]
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cyber/test-project/config"
+	"github.com/cyber/test-project/metrics"
+)
+
+type sharedResult struct {
+	value string
+	first bool
+	err   error
+}
+
+func TestSharedJoinsByDeadline(t *testing.T) {
+	tests := []struct {
+		name string
+		// Deadlines are offsets from a common start; zero means no deadline.
+		leaderDeadline   time.Duration
+		followerDeadline time.Duration
+		wantCalls        int32
+	}{
+		{name: "no deadlines", wantCalls: 1},
+		{name: "leader without deadline", followerDeadline: time.Minute, wantCalls: 1},
+		{name: "same deadline", leaderDeadline: time.Minute, followerDeadline: time.Minute, wantCalls: 1},
+		{name: "follower with earlier deadline", leaderDeadline: time.Hour, followerDeadline: time.Minute, wantCalls: 1},
+		{name: "follower with later deadline", leaderDeadline: time.Minute, followerDeadline: time.Hour, wantCalls: 2},
+		{name: "follower without deadline", leaderDeadline: time.Minute, wantCalls: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newRequestSharing(config.CoalescingConfig{Enabled: true}, config.HedgingConfig{})
+
+			var calls atomic.Int32
+			release := make(chan struct{})
+			fn := func(context.Context) (string, error) {
+				calls.Add(1)
+				<-release
+				return "users", nil
+			}
+
+			start := time.Now()
+			leaderCtx, cancel := withOptionalDeadline(start, tt.leaderDeadline)
+			defer cancel()
+			followerCtx, cancel := withOptionalDeadline(start, tt.followerDeadline)
+			defer cancel()
+
+			results := make(chan sharedResult, 2)
+			call := func(ctx context.Context) {
+				value, first, err := shared(ctx, s, ResourceUsers, "key", fn)
+				results <- sharedResult{value: value, first: first, err: err}
+			}
+
+			joined := coalescedUsers(t)
+			go call(leaderCtx)
+			waitFor(t, func() bool { return calls.Load() == 1 })
+			go call(followerCtx)
+			if tt.wantCalls == 1 {
+				waitFor(t, func() bool { return coalescedUsers(t) == joined+1 })
+			} else {
+				waitFor(t, func() bool { return calls.Load() == 2 })
+			}
+			close(release)
+
+			firsts := 0
+			for range 2 {
+				r := <-results
+				if r.err != nil || r.value != "users" {
+					t.Fatalf("shared() = %q, %v", r.value, r.err)
+				}
+				if r.first {
+					firsts++
+				}
+			}
+
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+			if firsts != int(tt.wantCalls) {
+				t.Errorf("first results = %d, want one per call (%d)", firsts, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestSharedOutlivesLeader(t *testing.T) {
+	s := newRequestSharing(config.CoalescingConfig{Enabled: true}, config.HedgingConfig{})
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	fn := func(ctx context.Context) (string, error) {
+		close(started)
+		<-release
+		return "users", ctx.Err()
+	}
+
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	leaderDone := make(chan error, 1)
+	go func() {
+		_, _, err := shared(leaderCtx, s, ResourceUsers, "key", fn)
+		leaderDone <- err
+	}()
+	<-started
+
+	joined := coalescedUsers(t)
+	followerDone := make(chan sharedResult, 1)
+	go func() {
+		value, first, err := shared(context.Background(), s, ResourceUsers, "key", fn)
+		followerDone <- sharedResult{value: value, first: first, err: err}
+	}()
+	waitFor(t, func() bool { return coalescedUsers(t) == joined+1 })
+
+	cancelLeader()
+	if err := <-leaderDone; err == nil {
+		t.Fatal("canceled leader got no error")
+	}
+	close(release)
+
+	r := <-followerDone
+	if r.err != nil || r.value != "users" || !r.first {
+		t.Errorf("follower got %+v, want the value of the call as the first receiver", r)
+	}
+}
+
+func withOptionalDeadline(start time.Time, offset time.Duration) (context.Context, context.CancelFunc) {
+	if offset == 0 {
+		return context.WithCancel(context.Background())
+	}
+
+	return context.WithDeadline(context.Background(), start.Add(offset))
+}
+
+// coalescedUsers returns how many users requests joined an in-flight call so far.
+func coalescedUsers(t *testing.T) float64 {
+	t.Helper()
+
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, family := range families {
+		if family.GetName() != "test_project_asana_coalesced_requests_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "resource_type" && label.GetValue() == ResourceUsers {
+					return metric.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+
+	return 0
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within 1s")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -1,22 +1,46 @@
 package shutdown
 
 import (
+	"context"
 	"os"
 	"os/signal"
+	"syscall"
 )
 
-type Shutdowner interface {
-	Shutdown()
+type Reloader interface {
+	Reload()
 }
 
-func ListenForSignals(signals []os.Signal, shutdowners ...Shutdowner) {
+// ListenForSignals returns a context that is cancelled once one of the signals is received.
+// Until then, reloaders are reloaded on every SIGHUP.
+func ListenForSignals(parent context.Context, signals []os.Signal, reloaders ...Reloader) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, signals...)
-	<-c
-
-	for _, shutdowner := range shutdowners {
-		shutdowner.Shutdown()
+	if len(reloaders) > 0 {
+		signal.Notify(c, syscall.SIGHUP)
 	}
 
-	os.Exit(0)
+	go func() {
+		defer signal.Stop(c)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case sig := <-c:
+				if sig != syscall.SIGHUP {
+					cancel()
+					return
+				}
+
+				for _, reloader := range reloaders {
+					reloader.Reload()
+				}
+			}
+		}
+	}()
+
+	return ctx, cancel
 }
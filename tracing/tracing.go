@@ -0,0 +1,140 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/cyber/test-project/appcontext"
+	"github.com/cyber/test-project/config"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+
+	instrumentationName = "github.com/cyber/test-project"
+)
+
+type ShutdownFunc func(ctx context.Context) error
+
+type options struct {
+	exporter sdktrace.SpanExporter
+}
+
+type Option func(*options)
+
+// WithExporter overrides the configured exporter, e.g. with tracetest.NewInMemoryExporter in tests.
+// Spans are exported synchronously so that they can be inspected right after the traced call returns.
+func WithExporter(exporter sdktrace.SpanExporter) Option {
+	return func(o *options) {
+		o.exporter = exporter
+	}
+}
+
+// Init installs the global tracer provider and the W3C trace context propagator.
+func Init(ctx context.Context, cfg config.TracingConfig, opts ...Option) (ShutdownFunc, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	providerOpts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(*cfg.SampleRatio))),
+	}
+
+	if o.exporter != nil {
+		providerOpts = append(providerOpts, sdktrace.WithSyncer(o.exporter))
+	} else {
+		exporter, err := newExporter(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		if exporter == nil {
+			return func(context.Context) error { return nil }, nil
+		}
+
+		providerOpts = append(providerOpts, sdktrace.WithBatcher(exporter))
+	}
+
+	provider := sdktrace.NewTracerProvider(providerOpts...)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+func newExporter(ctx context.Context, cfg config.TracingConfig) (sdktrace.SpanExporter, error) {
+	switch cfg.Exporter {
+	case ExporterNone:
+		return nil, nil
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterOTLP:
+		clientOpts := []otlptracehttp.Option{
+			otlptracehttp.WithEndpoint(cfg.OTLPEndpoint),
+		}
+		if cfg.OTLPInsecure {
+			clientOpts = append(clientOpts, otlptracehttp.WithInsecure())
+		}
+
+		return otlptracehttp.New(ctx, clientOpts...)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", cfg.Exporter)
+	}
+}
+
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Start starts a span. When the span is the first local span of a trace, its trace and span IDs are
+// added to the context logger, so every log line written while handling it can be correlated.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	parent := trace.SpanContextFromContext(ctx)
+
+	ctx, span := Tracer().Start(ctx, name, opts...)
+
+	if span.SpanContext().IsValid() && (!parent.IsValid() || parent.IsRemote()) {
+		logger := appcontext.Logger(ctx).With(
+			zap.String("trace_id", appcontext.TraceID(ctx)),
+			zap.String("span_id", appcontext.SpanID(ctx)),
+		)
+		ctx = appcontext.WithLogger(ctx, logger)
+	}
+
+	return ctx, span
+}
+
+func Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, carrier)
+}
+
+func Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+}
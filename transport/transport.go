@@ -3,19 +3,27 @@ package transport
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"go.uber.org/zap"
 
-	"github.com/cyber/test-project/logging"
+	"github.com/cyber/test-project/appcontext"
+	"github.com/cyber/test-project/models"
 )
 
+type ErrorResponse struct {
+	Error     string              `json:"error"`
+	Fields    []models.FieldError `json:"fields,omitempty"`
+	RequestID string              `json:"request_id,omitempty"`
+}
+
 func SendJson(ctx context.Context, w http.ResponseWriter, statusCode int, body any) {
 	w.Header().Set("Content-Type", "application/json")
 
 	bodyBytes, err := json.Marshal(body)
 	if err != nil {
-		logging.Logger.Error("error marshalling body", zap.Error(err))
+		appcontext.Logger(ctx).Error("error marshalling body", zap.Error(err))
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
@@ -23,10 +31,51 @@ func SendJson(ctx context.Context, w http.ResponseWriter, statusCode int, body a
 	w.WriteHeader(statusCode)
 	_, err = w.Write(bodyBytes)
 	if err != nil {
-		logging.Logger.Error("error writing body", zap.Error(err))
+		appcontext.Logger(ctx).Error("error writing body", zap.Error(err))
 	}
 }
 
+// SendError responds with 400 to invalid requests, 413 to oversized bodies, 404 when the upstream resource does not exist,
+// 504 when the request ran out of its time budget and 500 otherwise.
 func SendError(ctx context.Context, w http.ResponseWriter, err error) {
-	SendJson(ctx, w, http.StatusInternalServerError, err)
+	SendErrorStatus(ctx, w, errorStatus(err), err)
+}
+
+func errorStatus(err error) int {
+	var validationErr models.ErrValidation
+	if errors.As(err, &validationErr) {
+		return http.StatusBadRequest
+	}
+
+	var tooLargeErr models.ErrRequestTooLarge
+	if errors.As(err, &tooLargeErr) {
+		return http.StatusRequestEntityTooLarge
+	}
+
+	// Resources missing upstream are missing here too; other upstream rejections are our fault.
+	var responseErr models.ErrServiceResponse
+	if errors.As(err, &responseErr) && responseErr.StatusCode == http.StatusNotFound {
+		return http.StatusNotFound
+	}
+
+	var deadlineErr models.ErrDeadlineExceeded
+	if errors.As(err, &deadlineErr) || errors.Is(err, context.DeadlineExceeded) {
+		return http.StatusGatewayTimeout
+	}
+
+	return http.StatusInternalServerError
+}
+
+func SendErrorStatus(ctx context.Context, w http.ResponseWriter, statusCode int, err error) {
+	response := ErrorResponse{
+		Error:     err.Error(),
+		RequestID: appcontext.RequestID(ctx),
+	}
+
+	var validationErr models.ErrValidation
+	if errors.As(err, &validationErr) {
+		response.Fields = validationErr.Fields
+	}
+
+	SendJson(ctx, w, statusCode, response)
 }
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/cyber/test-project/config"
+)
+
+func newValidateConfigFlags() (*flag.FlagSet, *string) {
+	flags := flag.NewFlagSet("validate-config", flag.ExitOnError)
+	configPath := flags.String("config", "config.yaml", "Path to configuration file")
+
+	return flags, configPath
+}
+
+func validateConfig(args []string) int {
+	flags, configPath := newValidateConfigFlags()
+	_ = flags.Parse(args)
+
+	cfg, err := config.ReadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	encoder := yaml.NewEncoder(os.Stdout)
+	encoder.SetIndent(2)
+	err = encoder.Encode(cfg.Masked())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	return 0
+}
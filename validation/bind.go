@@ -0,0 +1,185 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/cyber/test-project/models"
+)
+
+// BindQuery fills dst, a pointer to a struct, from the query parameters named by the `query` tags of
+// its fields and validates it. A missing or empty parameter takes the value of the `default` tag, if
+// any. Supported field types are string, int, bool and *bool.
+func BindQuery(query url.Values, dst any) error {
+	return bind(dst, "query", query.Get)
+}
+
+// BindPath is BindQuery for the path variables of a route, named by `path` tags.
+func BindPath(vars map[string]string, dst any) error {
+	return bind(dst, "path", func(name string) string { return vars[name] })
+}
+
+func bind(dst any, tag string, lookup func(name string) string) error {
+	v := reflect.ValueOf(dst).Elem()
+	t := v.Type()
+
+	rules, err := rulesOf(t, tag)
+	if err != nil {
+		return err
+	}
+
+	var errs fieldErrors
+	set := make([]bool, t.NumField())
+	failed := make([]bool, t.NumField())
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, ok := field.Tag.Lookup(tag)
+		if !ok {
+			continue
+		}
+
+		raw := lookup(name)
+		if raw == "" {
+			raw = field.Tag.Get("default")
+		}
+
+		if raw == "" {
+			continue
+		}
+
+		set[i] = true
+		if err := setValue(v.Field(i), raw); err != nil {
+			failed[i] = true
+			errs.add(name, err.Error())
+		}
+	}
+
+	errs = append(errs, validate(v, rules, tag, func(i int) bool { return set[i] && !failed[i] })...)
+
+	return errs.err()
+}
+
+// MaxBodyBytes is the largest request body BindJSON reads.
+const MaxBodyBytes = 1 << 20
+
+// BindJSON decodes the JSON body of r into dst, a pointer to a struct, and validates it. Fields are
+// named by their `json` tags in errors. Bodies over MaxBodyBytes are rejected without reading them
+// further.
+func BindJSON(w http.ResponseWriter, r *http.Request, dst any) error {
+	v := reflect.ValueOf(dst).Elem()
+
+	rules, err := rulesOf(v.Type(), "json")
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxBodyBytes))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(dst); err != nil {
+		var tooLargeErr *http.MaxBytesError
+		if errors.As(err, &tooLargeErr) {
+			return models.ErrRequestTooLarge{Limit: tooLargeErr.Limit}
+		}
+
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return models.ErrValidation{Fields: []models.FieldError{{Field: typeErr.Field, Message: "must be a " + typeErr.Type.String()}}}
+		}
+
+		return models.ErrValidation{Fields: []models.FieldError{{Field: "body", Message: err.Error()}}}
+	}
+
+	return validate(v, rules, "json", func(i int) bool { return !v.Field(i).IsZero() }).err()
+}
+
+// Field describes a field bound by BindQuery or BindPath, for documentation.
+type Field struct {
+	Name    string
+	Type    reflect.Type
+	Default string
+	Rules   []Rule
+}
+
+// Fields describes the fields of the struct type of v that are bound from the given tag, `query`
+// or `path`.
+func Fields(v any, tag string) []Field {
+	t := reflect.TypeOf(v)
+
+	var fields []Field
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, ok := field.Tag.Lookup(tag)
+		if !ok {
+			continue
+		}
+
+		fields = append(fields, Field{
+			Name:    name,
+			Type:    field.Type,
+			Default: field.Tag.Get("default"),
+			Rules:   parseRules(field.Tag.Get("validate")),
+		})
+	}
+
+	return fields
+}
+
+func setValue(field reflect.Value, raw string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return errors.New("must be an integer")
+		}
+		field.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return errors.New("must be true or false")
+		}
+		field.SetBool(b)
+	case reflect.Pointer:
+		value := reflect.New(field.Type().Elem())
+		if err := setValue(value.Elem(), raw); err != nil {
+			return err
+		}
+		field.Set(value)
+	default:
+		return fmt.Errorf("has unsupported type %s", field.Type())
+	}
+
+	return nil
+}
+
+type fieldErrors []models.FieldError
+
+func (e *fieldErrors) add(field, message string) {
+	*e = append(*e, models.FieldError{Field: field, Message: message})
+}
+
+func (e fieldErrors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+
+	return models.ErrValidation{Fields: e}
+}
+
+// fieldName returns the name a field is bound from by the given tag, e.g. `query:"limit"`.
+func fieldName(field reflect.StructField, tag string) string {
+	name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+	if name == "" {
+		return field.Name
+	}
+
+	return name
+}
@@ -0,0 +1,73 @@
+package validation
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/cyber/test-project/models"
+)
+
+type testBody struct {
+	Level      string            `json:"level" validate:"oneof=debug info"`
+	Name       string            `json:"name" validate:"max=5"`
+	Components map[string]string `json:"components"`
+}
+
+func TestBindJSON(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantFields []string
+		wantLimit  bool
+	}{
+		{name: "valid", body: `{"level": "debug", "components": {"clients": "info"}}`},
+		{name: "empty object", body: `{}`},
+		{name: "rule", body: `{"level": "verbose"}`, wantFields: []string{"level"}},
+		{name: "type", body: `{"name": 5}`, wantFields: []string{"name"}},
+		{name: "unknown field", body: `{"lvl": "debug"}`, wantFields: []string{"body"}},
+		{name: "malformed", body: `{"level": `, wantFields: []string{"body"}},
+		{name: "at the limit", body: `{"name": "Ada"}` + strings.Repeat(" ", MaxBodyBytes-len(`{"name": "Ada"}`))},
+		{name: "over the limit", body: `{"name": "` + strings.Repeat("a", MaxBodyBytes) + `"}`, wantLimit: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPut, "/admin/log-level", strings.NewReader(tt.body))
+
+			var body testBody
+			err := BindJSON(httptest.NewRecorder(), request, &body)
+
+			var tooLargeErr models.ErrRequestTooLarge
+			if tt.wantLimit {
+				if !errors.As(err, &tooLargeErr) || tooLargeErr.Limit != MaxBodyBytes {
+					t.Fatalf("BindJSON() = %#v, want ErrRequestTooLarge with limit %d", err, MaxBodyBytes)
+				}
+				return
+			}
+
+			if got := fields(t, err); !slices.Equal(got, tt.wantFields) {
+				t.Errorf("invalid fields = %v, want %v", got, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestBindPath(t *testing.T) {
+	var path struct {
+		Gid string `path:"gid" validate:"required,user"`
+	}
+
+	for _, gid := range []string{"1201", "me", "ada@example.com"} {
+		if err := BindPath(map[string]string{"gid": gid}, &path); err != nil || path.Gid != gid {
+			t.Errorf("BindPath(%q) = %v, bound %q", gid, err, path.Gid)
+		}
+	}
+
+	if got := fields(t, BindPath(map[string]string{}, &path)); !slices.Equal(got, []string{"gid"}) {
+		t.Errorf("invalid fields without the variable = %v, want [gid]", got)
+	}
+}
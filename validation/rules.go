@@ -0,0 +1,238 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// validate checks the fields of v against their `validate` tag, a comma separated list of rules:
+//
+//	required        the field must be set
+//	min=N, max=N    bounds of an int, or of the length of a string
+//	gid             an Asana gid, i.e. digits only
+//	user            a user gid, me or an email address, as Asana accepts for a user
+//	oneof=a b c     one of the listed values
+//	excludes=name   must not be set together with the field bound from name
+//
+// Rules other than required only apply to fields that are set. Fields are named by tag in errors.
+// The rules come from rulesOf, so they are known to be valid.
+func validate(v reflect.Value, rules [][]Rule, tag string, isSet func(i int) bool) fieldErrors {
+	t := v.Type()
+
+	var errs fieldErrors
+	for i := range t.NumField() {
+		name := fieldName(t.Field(i), tag)
+		for _, rule := range rules[i] {
+			if rule.Name == "required" {
+				if !isSet(i) {
+					errs.add(name, "is required")
+				}
+				continue
+			}
+
+			if !isSet(i) {
+				continue
+			}
+
+			if message := check(v, tag, isSet, v.Field(i), rule.Name, rule.Arg); message != "" {
+				errs.add(name, message)
+			}
+		}
+	}
+
+	return errs
+}
+
+func check(v reflect.Value, tag string, isSet func(i int) bool, field reflect.Value, rule, arg string) string {
+	field = reflect.Indirect(field)
+
+	switch rule {
+	case "min", "max":
+		bound, _ := strconv.Atoi(arg)
+
+		n, unit := size(field)
+		if rule == "min" && n < bound {
+			return fmt.Sprintf("must be at least %d%s", bound, unit)
+		}
+		if rule == "max" && n > bound {
+			return fmt.Sprintf("must be at most %d%s", bound, unit)
+		}
+	case "gid":
+		if !isGid(field.String()) {
+			return "must be a gid of digits only"
+		}
+	case "user":
+		if !isUser(field.String()) {
+			return "must be a gid of digits only, me or an email address"
+		}
+	case "oneof":
+		values := strings.Fields(arg)
+		if !slices.Contains(values, fmt.Sprint(field.Interface())) {
+			return "must be one of " + strings.Join(values, ", ")
+		}
+	case "excludes":
+		for j := range v.NumField() {
+			if fieldName(v.Type().Field(j), tag) == arg && isSet(j) {
+				return "cannot be combined with " + arg
+			}
+		}
+	}
+
+	return ""
+}
+
+// Check reports invalid `validate` tags on the struct type of v and, for the `query` and `path`
+// tags, fields of unsupported types or with an invalid `default`. Types are checked when their
+// routes are registered, so that a broken tag fails startup rather than a request.
+func Check(v any, tag string) error {
+	_, err := rulesOf(reflect.TypeOf(v), tag)
+
+	return err
+}
+
+type rulesKey struct {
+	t   reflect.Type
+	tag string
+}
+
+type checkedRules struct {
+	rules [][]Rule
+	err   error
+}
+
+// rules caches the checked rules of every struct type and tag.
+var rules sync.Map
+
+// rulesOf returns the rules of every field of t, or the error of Check.
+func rulesOf(t reflect.Type, tag string) ([][]Rule, error) {
+	key := rulesKey{t: t, tag: tag}
+	if cached, ok := rules.Load(key); ok {
+		return cached.(checkedRules).rules, cached.(checkedRules).err
+	}
+
+	fieldRules, err := checkRules(t, tag)
+	rules.Store(key, checkedRules{rules: fieldRules, err: err})
+
+	return fieldRules, err
+}
+
+func checkRules(t reflect.Type, tag string) ([][]Rule, error) {
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("validation: %v is not a struct", t)
+	}
+
+	var errs []error
+	fieldRules := make([][]Rule, t.NumField())
+	for i := range t.NumField() {
+		field := t.Field(i)
+		fail := func(format string, args ...any) {
+			errs = append(errs, fmt.Errorf("validation: %s.%s: %s", t.Name(), field.Name, fmt.Sprintf(format, args...)))
+		}
+
+		fieldRules[i] = parseRules(field.Tag.Get("validate"))
+		for _, rule := range fieldRules[i] {
+			switch rule.Name {
+			case "required", "gid", "user":
+			case "min", "max":
+				if _, err := strconv.Atoi(rule.Arg); err != nil {
+					fail("invalid %s bound %q", rule.Name, rule.Arg)
+				}
+			case "oneof":
+				if len(strings.Fields(rule.Arg)) == 0 {
+					fail("oneof lists no values")
+				}
+			case "excludes":
+				if !slices.ContainsFunc(reflect.VisibleFields(t), func(other reflect.StructField) bool { return fieldName(other, tag) == rule.Arg }) {
+					fail("excludes unknown field %q", rule.Arg)
+				}
+			default:
+				fail("unknown rule %q", rule.Name)
+			}
+		}
+
+		if _, bound := field.Tag.Lookup(tag); !bound || tag == "json" {
+			continue
+		}
+		if !bindable(field.Type) {
+			fail("unsupported field type %s", field.Type)
+		} else if def, ok := field.Tag.Lookup("default"); ok {
+			if err := setValue(reflect.New(field.Type).Elem(), def); err != nil {
+				fail("default %q %s", def, err)
+			}
+		}
+	}
+
+	return fieldRules, errors.Join(errs...)
+}
+
+// bindable reports whether setValue supports fields of type t.
+func bindable(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Int, reflect.Bool:
+		return true
+	default:
+		return false
+	}
+}
+
+func size(field reflect.Value) (int, string) {
+	if field.Kind() == reflect.String {
+		return len(field.String()), " characters long"
+	}
+
+	return int(field.Int()), ""
+}
+
+func isGid(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isUser(s string) bool {
+	if s == "me" || isGid(s) {
+		return true
+	}
+
+	address, err := mail.ParseAddress(s)
+
+	return err == nil && address.Name == "" && address.Address == s
+}
+
+// Rule is one rule of a `validate` tag, e.g. max=100.
+type Rule struct {
+	Name string
+	Arg  string
+}
+
+func parseRules(tag string) []Rule {
+	if tag == "" {
+		return nil
+	}
+
+	var rules []Rule
+	for _, rule := range strings.Split(tag, ",") {
+		name, arg, _ := strings.Cut(rule, "=")
+		rules = append(rules, Rule{Name: name, Arg: arg})
+	}
+
+	return rules
+}
@@ -0,0 +1,156 @@
+package validation
+
+import (
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/cyber/test-project/models"
+)
+
+type testQuery struct {
+	Workspace string `query:"workspace" validate:"required,gid"`
+	Team      string `query:"team" validate:"gid,excludes=workspace"`
+	Assignee  string `query:"assignee" validate:"user"`
+	Name      string `query:"name" validate:"min=2,max=5"`
+	Limit     int    `query:"limit" default:"20" validate:"min=1,max=100"`
+	Sort      string `query:"sort" validate:"oneof=name created_at"`
+	Archived  *bool  `query:"archived"`
+}
+
+// fields returns the names of the fields of a validation error.
+func fields(t *testing.T, err error) []string {
+	t.Helper()
+
+	if err == nil {
+		return nil
+	}
+
+	var validationErr models.ErrValidation
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("error = %#v, want a validation error", err)
+	}
+
+	var names []string
+	for _, field := range validationErr.Fields {
+		names = append(names, field.Field)
+	}
+
+	return names
+}
+
+func TestBindQueryRules(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantFields []string
+	}{
+		{name: "valid", query: "workspace=1000&assignee=me&name=Ada&limit=100&sort=name&archived=true"},
+		{name: "required", query: "limit=10", wantFields: []string{"workspace"}},
+		{name: "gid", query: "workspace=10a0", wantFields: []string{"workspace"}},
+		{name: "excludes", query: "workspace=1000&team=2000", wantFields: []string{"team"}},
+		{name: "user gid", query: "workspace=1000&assignee=1201"},
+		{name: "user email", query: "workspace=1000&assignee=ada@example.com"},
+		{name: "user with display name", query: "workspace=1000&assignee=" + url.QueryEscape("Ada <ada@example.com>"), wantFields: []string{"assignee"}},
+		{name: "user name", query: "workspace=1000&assignee=ada", wantFields: []string{"assignee"}},
+		{name: "min length", query: "workspace=1000&name=A", wantFields: []string{"name"}},
+		{name: "max length", query: "workspace=1000&name=Lovelace", wantFields: []string{"name"}},
+		{name: "min", query: "workspace=1000&limit=0", wantFields: []string{"limit"}},
+		{name: "max", query: "workspace=1000&limit=101", wantFields: []string{"limit"}},
+		{name: "not an integer", query: "workspace=1000&limit=ten", wantFields: []string{"limit"}},
+		{name: "oneof", query: "workspace=1000&sort=gid", wantFields: []string{"sort"}},
+		{name: "not a bool", query: "workspace=1000&archived=maybe", wantFields: []string{"archived"}},
+		{name: "every error at once", query: "team=x&limit=0", wantFields: []string{"workspace", "team", "limit"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var query testQuery
+			got := fields(t, BindQuery(values, &query))
+			if !slices.Equal(got, tt.wantFields) {
+				t.Errorf("invalid fields = %v, want %v", got, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestBindQueryDefaults(t *testing.T) {
+	var query testQuery
+	if err := BindQuery(url.Values{"workspace": {"1000"}}, &query); err != nil {
+		t.Fatal(err)
+	}
+	if query.Limit != 20 || query.Archived != nil {
+		t.Errorf("limit = %d, archived = %v, want the default 20 and nil", query.Limit, query.Archived)
+	}
+
+	if err := BindQuery(url.Values{"workspace": {"1000"}, "limit": {"5"}, "archived": {"false"}}, &query); err != nil {
+		t.Fatal(err)
+	}
+	if query.Limit != 5 || query.Archived == nil || *query.Archived {
+		t.Errorf("limit = %d, archived = %v, want 5 and false", query.Limit, query.Archived)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       any
+		tag     string
+		wantErr string
+	}{
+		{name: "valid query", v: testQuery{}, tag: "query"},
+		{name: "valid json", v: struct {
+			Level string `json:"level" validate:"oneof=debug info"`
+		}{}, tag: "json"},
+		{name: "unknown rule", v: struct {
+			Gid string `path:"gid" validate:"required,gids"`
+		}{}, tag: "path", wantErr: `unknown rule "gids"`},
+		{name: "invalid bound", v: struct {
+			Limit int `query:"limit" validate:"max=many"`
+		}{}, tag: "query", wantErr: `invalid max bound "many"`},
+		{name: "oneof without values", v: struct {
+			Sort string `query:"sort" validate:"oneof="`
+		}{}, tag: "query", wantErr: "oneof lists no values"},
+		{name: "excludes unknown field", v: struct {
+			Team string `query:"team" validate:"excludes=workspaces"`
+		}{}, tag: "query", wantErr: `excludes unknown field "workspaces"`},
+		{name: "unsupported type", v: struct {
+			Limit float64 `query:"limit"`
+		}{}, tag: "query", wantErr: "unsupported field type float64"},
+		{name: "invalid default", v: struct {
+			Limit int `query:"limit" default:"ten"`
+		}{}, tag: "query", wantErr: `default "ten" must be an integer`},
+		{name: "not a struct", v: "gid", tag: "path", wantErr: "not a struct"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Check(tt.v, tt.tag)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Check() = %v, want nil", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("Check() = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBindWithInvalidTagsFailsWithoutPanicking(t *testing.T) {
+	var path struct {
+		Gid string `path:"gid" validate:"gids"`
+	}
+
+	err := BindPath(map[string]string{"gid": "1201"}, &path)
+	var validationErr models.ErrValidation
+	if err == nil || errors.As(err, &validationErr) {
+		t.Errorf("BindPath() = %v, want an internal error for the broken tag", err)
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"runtime/debug"
+)
+
+// version is set at build time with `-ldflags "-X main.version=..."`.
+var version = "dev"
+
+func printVersion([]string) int {
+	writeVersion(os.Stdout)
+
+	return 0
+}
+
+func writeVersion(w io.Writer) {
+	fmt.Fprintf(w, "test_app %s\n", version)
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" || setting.Key == "vcs.time" || setting.Key == "vcs.modified" {
+				fmt.Fprintf(w, "%s: %s\n", setting.Key, setting.Value)
+			}
+		}
+	}
+	fmt.Fprintf(w, "go: %s\n", runtime.Version())
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestWriteVersion(t *testing.T) {
+	var out bytes.Buffer
+	writeVersion(&out)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if lines[0] != "test_app "+version {
+		t.Errorf("first line = %q, want the version", lines[0])
+	}
+	if last := lines[len(lines)-1]; last != "go: "+runtime.Version() {
+		t.Errorf("last line = %q, want the Go version", last)
+	}
+}